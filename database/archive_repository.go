@@ -0,0 +1,75 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SavePageArchive 压缩保存页面存档，并按数量和天数清理该站点的旧存档。
+// maxCount 或 maxAge 为 0 时不按对应条件清理。
+func SavePageArchive(archive *PageArchive, body []byte, maxCount int, maxAge time.Duration) error {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return fmt.Errorf("压缩页面存档失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("压缩页面存档失败: %w", err)
+	}
+	archive.Body = buf.Bytes()
+	archive.Size = len(body)
+	if err := DB.Create(archive).Error; err != nil {
+		return fmt.Errorf("保存页面存档失败: %w", err)
+	}
+
+	if maxAge > 0 {
+		if err := DB.Where("site_id = ? AND created_at < ?", archive.SiteID, time.Now().Add(-maxAge)).Delete(&PageArchive{}).Error; err != nil {
+			return fmt.Errorf("清理过期页面存档失败: %w", err)
+		}
+	}
+	if maxCount > 0 {
+		keep := DB.Model(&PageArchive{}).Select("id").Where("site_id = ?", archive.SiteID).Order("id desc").Limit(maxCount)
+		if err := DB.Where("site_id = ? AND id NOT IN (?)", archive.SiteID, keep).Delete(&PageArchive{}).Error; err != nil {
+			return fmt.Errorf("清理超量页面存档失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListPageArchives 按时间倒序列出站点的页面存档，不加载正文。
+func ListPageArchives(siteID uint, limit int) ([]PageArchive, error) {
+	var archives []PageArchive
+	if err := DB.Omit("body").Where("site_id = ?", siteID).Order("id desc").Limit(limit).Find(&archives).Error; err != nil {
+		return nil, fmt.Errorf("读取页面存档失败: %w", err)
+	}
+	return archives, nil
+}
+
+// LoadPageArchive 读取站点的指定存档，不存在时返回 nil。
+func LoadPageArchive(siteID, archiveID uint) (*PageArchive, error) {
+	var archives []PageArchive
+	if err := DB.Where("site_id = ? AND id = ?", siteID, archiveID).Limit(1).Find(&archives).Error; err != nil {
+		return nil, fmt.Errorf("读取页面存档失败: %w", err)
+	}
+	if len(archives) == 0 {
+		return nil, nil
+	}
+	return &archives[0], nil
+}
+
+// Content 返回解压后的页面正文。
+func (a *PageArchive) Content() ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(a.Body))
+	if err != nil {
+		return nil, fmt.Errorf("解压页面存档失败: %w", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("解压页面存档失败: %w", err)
+	}
+	return data, nil
+}
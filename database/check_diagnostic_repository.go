@@ -0,0 +1,30 @@
+package database
+
+import "fmt"
+
+// SaveCheckDiagnostic 保存一次检查的字段统计，并只保留该站点最近 maxCount 条记录。
+func SaveCheckDiagnostic(diagnostic *CheckDiagnostic, maxCount int) error {
+	if err := DB.Create(diagnostic).Error; err != nil {
+		return fmt.Errorf("保存检查诊断失败: %w", err)
+	}
+	if maxCount > 0 {
+		keep := DB.Model(&CheckDiagnostic{}).Select("id").Where("site_id = ?", diagnostic.SiteID).Order("id desc").Limit(maxCount)
+		if err := DB.Where("site_id = ? AND id NOT IN (?)", diagnostic.SiteID, keep).Delete(&CheckDiagnostic{}).Error; err != nil {
+			return fmt.Errorf("清理旧检查诊断失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListCheckDiagnostics 按时间倒序列出站点指定定义版本的检查诊断，definitionVersion 为 0 时不限版本。
+func ListCheckDiagnostics(siteID uint, definitionVersion int, limit int) ([]CheckDiagnostic, error) {
+	query := DB.Where("site_id = ?", siteID)
+	if definitionVersion > 0 {
+		query = query.Where("definition_version = ?", definitionVersion)
+	}
+	var diagnostics []CheckDiagnostic
+	if err := query.Order("id desc").Limit(limit).Find(&diagnostics).Error; err != nil {
+		return nil, fmt.Errorf("读取检查诊断失败: %w", err)
+	}
+	return diagnostics, nil
+}
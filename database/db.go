@@ -22,7 +22,7 @@ func Init(dbPath string) error {
 	}
 
 	// 自动迁移 Schema
-	if err := DB.AutoMigrate(&Site{}, &SiteField{}, &UpdateRecord{}, &NotificationAccount{}, &ScanRuleTemplate{}, &ScanRuleField{}, &SystemSetting{}, &MonitorSnapshot{}, &MonitorEvent{}, &NotificationDelivery{}); err != nil {
+	if err := DB.AutoMigrate(&Site{}, &SiteField{}, &UpdateRecord{}, &NotificationAccount{}, &ScanRuleTemplate{}, &ScanRuleField{}, &SystemSetting{}, &MonitorSnapshot{}, &MonitorEvent{}, &NotificationDelivery{}, &FetchState{}, &PageArchive{}, &SiteSession{}, &CheckDiagnostic{}, &SelectorRepair{}); err != nil {
 		return err
 	}
 
@@ -30,6 +30,7 @@ func Init(dbPath string) error {
 	DB.Model(&Site{}).Where("strategy_type = ''").Update("strategy_type", "presence")
 	DB.Model(&Site{}).Where("baseline_status = ''").Update("baseline_status", "pending")
 	DB.Model(&Site{}).Where("config_version = 0").Update("config_version", 1)
+	DB.Model(&Site{}).Where("extract_mode = '' OR extract_mode IS NULL").Update("extract_mode", "html")
 	DB.Model(&MonitorEvent{}).Where("delivery_status = ''").Update("delivery_status", "pending")
 
 	log.Printf("[DB] 数据库就绪: %s", dbPath)
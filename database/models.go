@@ -39,6 +39,8 @@ type Site struct {
 	ConfigVersion int `gorm:"default:1"`
 	// DataType 字段数据类型映射（JSON），如 {"price":"money","title":"text"}
 	FieldDataTypes string `gorm:"type:text"`
+	// ExtractMode 提取模式: html（CSS 选择器）, json（JSONPath）
+	ExtractMode string `gorm:"size:20;default:html"`
 }
 
 // SiteField 提取字段配置
@@ -52,15 +54,19 @@ type SiteField struct {
 	Type      string `gorm:"size:20;default:text"`
 	Attr      string `gorm:"size:50"`
 	Transform string `gorm:"size:255"`
+	// Scope 字段作用范围：list 从列表条目提取，detail 从条目链接指向的详情页提取
+	Scope string `gorm:"size:20;default:list"`
 }
 
 // UpdateRecord 变更历史记录
 type UpdateRecord struct {
-	ID         uint       `gorm:"primarykey"`
-	CreatedAt  time.Time  `gorm:"index"`
-	SiteID     uint       `gorm:"index"`
-	Title      string     `gorm:"size:500"`
-	URL        string     `gorm:"size:512"`
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+	SiteID    uint      `gorm:"index"`
+	Title     string    `gorm:"size:500"`
+	URL       string    `gorm:"size:512"`
+	// ItemKey 条目的稳定身份（如订阅源 guid），为空时按标题和链接去重
+	ItemKey    string     `gorm:"size:512;index"`
 	Summary    string     `gorm:"size:1000"`
 	Content    string     `gorm:"type:text"`
 	Notified   bool       `gorm:"default:false"`
@@ -156,8 +162,12 @@ type MonitorEvent struct {
 	DedupeKey         string    `gorm:"uniqueIndex:idx_site_dedupe;size:64" json:"-"`
 	DefinitionVersion int       `gorm:"default:1" json:"definition_version"`
 	OccurredAt        time.Time `gorm:"index" json:"occurred_at"`
-	Notified          bool      `gorm:"default:false;index" json:"notified"`
-	DeliveryStatus    string    `gorm:"size:20;default:pending;index" json:"delivery_status"`
+	// PublishedAt 条目的发布时间，来自 datetime 类型的发布时间字段
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// FieldName field_changed 事件中发生变化的字段
+	FieldName      string `gorm:"size:100" json:"field_name,omitempty"`
+	Notified       bool   `gorm:"default:false;index" json:"notified"`
+	DeliveryStatus string `gorm:"size:20;default:pending;index" json:"delivery_status"`
 }
 
 func (MonitorEvent) TableName() string { return "monitor_events" }
@@ -180,6 +190,85 @@ type NotificationDelivery struct {
 
 func (NotificationDelivery) TableName() string { return "notification_deliveries" }
 
+// FetchState 站点最近一次成功抓取的 HTTP 校验值，用于条件请求
+type FetchState struct {
+	ID                uint `gorm:"primarykey"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	SiteID            uint   `gorm:"uniqueIndex"`
+	DefinitionVersion int    `gorm:"default:1"`
+	ETag              string `gorm:"size:255"`
+	LastModified      string `gorm:"size:100"`
+}
+
+func (FetchState) TableName() string { return "fetch_states" }
+
+// PageArchive 检查时抓取到的原始页面，正文以 gzip 压缩存储，用于排查和回放提取
+type PageArchive struct {
+	ID                uint      `gorm:"primarykey" json:"id"`
+	CreatedAt         time.Time `gorm:"index" json:"created_at"`
+	SiteID            uint      `gorm:"index" json:"site_id"`
+	DefinitionVersion int       `json:"definition_version"`
+	URL               string    `gorm:"size:2048" json:"url"`
+	Page              int       `json:"page"`
+	StatusCode        int       `json:"status_code"`
+	ContentType       string    `gorm:"size:255" json:"content_type"`
+	Size              int       `json:"size"`
+	Body              []byte    `json:"-"`
+}
+
+func (PageArchive) TableName() string { return "page_archives" }
+
+// CheckDiagnostic 一次检查中各字段的命中情况，FieldsJSON 为字段统计数组，用于排查选择器失效
+type CheckDiagnostic struct {
+	ID                uint      `gorm:"primarykey" json:"id"`
+	CreatedAt         time.Time `gorm:"index" json:"created_at"`
+	SiteID            uint      `gorm:"index" json:"site_id"`
+	DefinitionVersion int       `json:"definition_version"`
+	ItemCount         int       `json:"item_count"`
+	FieldsJSON        string    `gorm:"type:text" json:"-"`
+}
+
+func (CheckDiagnostic) TableName() string { return "check_diagnostics" }
+
+// SelectorRepair 选择器漂移时由智能扫描生成的提取配置修复建议
+type SelectorRepair struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	SiteID    uint      `gorm:"index" json:"site_id"`
+	// DefinitionVersion 生成建议时的配置版本，配置变化后建议失效
+	DefinitionVersion int    `json:"definition_version"`
+	Reason            string `gorm:"size:255" json:"reason"`
+	Strategy          string `gorm:"size:50" json:"strategy"`
+	Container         string `gorm:"size:255" json:"container"`
+	Item              string `gorm:"size:255" json:"item"`
+	FieldsJSON        string `gorm:"type:text" json:"-"`
+	ItemCount         int    `json:"item_count"`
+	// MatchedItems 新配置提取到的条目中身份与现有基线一致的数量
+	MatchedItems int `json:"matched_items"`
+	// KeepKeysJSON 身份仍匹配的快照 item_key，接受建议时保留
+	KeepKeysJSON string `gorm:"type:text" json:"-"`
+	SamplesJSON  string `gorm:"type:text" json:"-"`
+	// Status: pending, accepted, dismissed, stale；unavailable 表示扫描未找到候选，仅用于抑制重复扫描
+	Status string `gorm:"size:20;default:pending;index" json:"status"`
+}
+
+func (SelectorRepair) TableName() string { return "selector_repairs" }
+
+// SiteSession 站点登录会话的持久化 Cookie，Fingerprint 为会话配置摘要，配置变化后旧 Cookie 失效
+type SiteSession struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	SiteID      uint   `gorm:"uniqueIndex"`
+	Fingerprint string `gorm:"size:64"`
+	Cookies     string `gorm:"type:text"`
+	LoggedInAt  time.Time
+}
+
+func (SiteSession) TableName() string { return "site_sessions" }
+
 // SystemSetting 系统设置键值对
 type SystemSetting struct {
 	ID    uint   `gorm:"primarykey"`
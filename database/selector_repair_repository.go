@@ -0,0 +1,151 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FindPendingSelectorRepair 返回站点在指定定义版本下待处理的修复建议，不存在时返回 nil。
+func FindPendingSelectorRepair(siteID uint, definitionVersion int) (*SelectorRepair, error) {
+	var repairs []SelectorRepair
+	if err := DB.Where("site_id = ? AND definition_version = ? AND status = ?", siteID, definitionVersion, "pending").
+		Order("id desc").Limit(1).Find(&repairs).Error; err != nil {
+		return nil, fmt.Errorf("读取修复建议失败: %w", err)
+	}
+	if len(repairs) == 0 {
+		return nil, nil
+	}
+	return &repairs[0], nil
+}
+
+// FindSelectorRepair 返回站点在指定定义版本下最近的修复建议（任意状态），不存在时返回 nil。
+func FindSelectorRepair(siteID uint, definitionVersion int) (*SelectorRepair, error) {
+	var repairs []SelectorRepair
+	if err := DB.Where("site_id = ? AND definition_version = ?", siteID, definitionVersion).
+		Order("id desc").Limit(1).Find(&repairs).Error; err != nil {
+		return nil, fmt.Errorf("读取修复建议失败: %w", err)
+	}
+	if len(repairs) == 0 {
+		return nil, nil
+	}
+	return &repairs[0], nil
+}
+
+// MarkSelectorRepairUnavailable 记录指定定义版本的漂移扫描没有找到可用候选，已有标记时刷新其时间。
+func MarkSelectorRepairUnavailable(siteID uint, definitionVersion int, reason string) error {
+	var marker SelectorRepair
+	err := DB.Where("site_id = ? AND definition_version = ? AND status = ?", siteID, definitionVersion, "unavailable").
+		Attrs(SelectorRepair{Reason: reason}).
+		FirstOrCreate(&marker, SelectorRepair{SiteID: siteID, DefinitionVersion: definitionVersion, Status: "unavailable"}).Error
+	if err != nil {
+		return fmt.Errorf("记录修复建议扫描结果失败: %w", err)
+	}
+	if err := DB.Model(&marker).Updates(map[string]interface{}{"reason": reason, "updated_at": time.Now()}).Error; err != nil {
+		return fmt.Errorf("记录修复建议扫描结果失败: %w", err)
+	}
+	return nil
+}
+
+// CreateSelectorRepair 保存修复建议，同一定义版本已有待处理建议时不重复创建，返回已有的建议。
+func CreateSelectorRepair(repair *SelectorRepair) (*SelectorRepair, error) {
+	existing, err := FindPendingSelectorRepair(repair.SiteID, repair.DefinitionVersion)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+	repair.Status = "pending"
+	if err := DB.Create(repair).Error; err != nil {
+		return nil, fmt.Errorf("保存修复建议失败: %w", err)
+	}
+	return repair, nil
+}
+
+// ListSelectorRepairs 按时间倒序列出站点的修复建议，不包含无候选标记。
+func ListSelectorRepairs(siteID uint, limit int) ([]SelectorRepair, error) {
+	var repairs []SelectorRepair
+	if err := DB.Where("site_id = ? AND status <> ?", siteID, "unavailable").Order("id desc").Limit(limit).Find(&repairs).Error; err != nil {
+		return nil, fmt.Errorf("读取修复建议失败: %w", err)
+	}
+	return repairs, nil
+}
+
+// LoadSelectorRepair 读取站点的指定修复建议，不存在时返回 nil。
+func LoadSelectorRepair(siteID, repairID uint) (*SelectorRepair, error) {
+	var repairs []SelectorRepair
+	if err := DB.Where("site_id = ? AND id = ?", siteID, repairID).Limit(1).Find(&repairs).Error; err != nil {
+		return nil, fmt.Errorf("读取修复建议失败: %w", err)
+	}
+	if len(repairs) == 0 {
+		return nil, nil
+	}
+	return &repairs[0], nil
+}
+
+// DismissSelectorRepair 忽略待处理的修复建议。
+func DismissSelectorRepair(repairID uint) error {
+	result := DB.Model(&SelectorRepair{}).Where("id = ? AND status = ?", repairID, "pending").Update("status", "dismissed")
+	if result.Error != nil {
+		return fmt.Errorf("更新修复建议失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("修复建议不是待处理状态: %d", repairID)
+	}
+	return nil
+}
+
+// ErrSiteModified 应用修复建议期间监控配置已被修改。
+var ErrSiteModified = errors.New("监控配置已被修改，修复建议已失效")
+
+// ApplySelectorRepair 事务性地应用修复建议：保存新定义（site.ConfigVersion 已由调用方推进），
+// 将 keepKeys 对应的旧版本快照迁移到新版本并删除其余快照，最后标记建议已接受。
+// 只有站点仍处于 oldVersion 时才写入，期间被其他请求修改时返回 ErrSiteModified；
+// 任何步骤失败都会回滚，保留旧配置和旧快照。
+func ApplySelectorRepair(site *Site, fields []SiteField, repairID uint, oldVersion int, keepKeys []string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&SelectorRepair{}).
+			Where("id = ? AND site_id = ? AND definition_version = ? AND status = ?", repairID, site.ID, oldVersion, "pending").
+			Update("status", "accepted")
+		if result.Error != nil {
+			return fmt.Errorf("更新修复建议失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("修复建议已失效: %d", repairID)
+		}
+		// 按旧版本条件更新，避免覆盖加载之后其他请求保存的配置
+		result = tx.Model(&Site{}).Where("id = ? AND config_version = ?", site.ID, oldVersion).
+			Select("*").Omit("Fields", "ID", "CreatedAt").Updates(site)
+		if result.Error != nil {
+			return fmt.Errorf("保存站点失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrSiteModified
+		}
+		if err := tx.Where("site_id = ?", site.ID).Delete(&SiteField{}).Error; err != nil {
+			return fmt.Errorf("删除旧字段失败: %w", err)
+		}
+		if len(fields) > 0 {
+			for i := range fields {
+				fields[i].SiteID = site.ID
+			}
+			if err := tx.Create(&fields).Error; err != nil {
+				return fmt.Errorf("创建字段失败: %w", err)
+			}
+		}
+		if err := tx.Where("site_id = ?", site.ID).Delete(&FetchState{}).Error; err != nil {
+			return fmt.Errorf("删除抓取状态失败: %w", err)
+		}
+		if len(keepKeys) > 0 {
+			if err := tx.Model(&MonitorSnapshot{}).
+				Where("site_id = ? AND definition_version = ? AND item_key IN ?", site.ID, oldVersion, keepKeys).
+				Update("definition_version", site.ConfigVersion).Error; err != nil {
+				return fmt.Errorf("迁移快照失败: %w", err)
+			}
+		}
+		if err := tx.Where("site_id = ? AND definition_version <> ?", site.ID, site.ConfigVersion).Delete(&MonitorSnapshot{}).Error; err != nil {
+			return fmt.Errorf("删除旧快照失败: %w", err)
+		}
+		return nil
+	})
+}
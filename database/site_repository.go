@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -63,6 +64,10 @@ func UpdateMonitorDefinition(site *Site, fields []SiteField, resetBaseline bool)
 				return fmt.Errorf("创建字段失败: %w", err)
 			}
 		}
+		// 抓取配置可能已变化，旧校验值不再可信
+		if err := tx.Where("site_id = ?", site.ID).Delete(&FetchState{}).Error; err != nil {
+			return fmt.Errorf("删除抓取状态失败: %w", err)
+		}
 		if resetBaseline {
 			if err := tx.Where("site_id = ?", site.ID).Delete(&MonitorSnapshot{}).Error; err != nil {
 				return fmt.Errorf("删除旧快照失败: %w", err)
@@ -106,6 +111,21 @@ func DeleteSiteCascade(siteID uint) error {
 		if err := tx.Where("site_id = ?", siteID).Delete(&MonitorSnapshot{}).Error; err != nil {
 			return fmt.Errorf("删除快照失败: %w", err)
 		}
+		if err := tx.Where("site_id = ?", siteID).Delete(&FetchState{}).Error; err != nil {
+			return fmt.Errorf("删除抓取状态失败: %w", err)
+		}
+		if err := tx.Where("site_id = ?", siteID).Delete(&PageArchive{}).Error; err != nil {
+			return fmt.Errorf("删除页面存档失败: %w", err)
+		}
+		if err := tx.Where("site_id = ?", siteID).Delete(&SiteSession{}).Error; err != nil {
+			return fmt.Errorf("删除登录会话失败: %w", err)
+		}
+		if err := tx.Where("site_id = ?", siteID).Delete(&CheckDiagnostic{}).Error; err != nil {
+			return fmt.Errorf("删除检查诊断失败: %w", err)
+		}
+		if err := tx.Where("site_id = ?", siteID).Delete(&SelectorRepair{}).Error; err != nil {
+			return fmt.Errorf("删除修复建议失败: %w", err)
+		}
 		if err := tx.Where("site_id = ?", siteID).Delete(&UpdateRecord{}).Error; err != nil {
 			return fmt.Errorf("删除更新记录失败: %w", err)
 		}
@@ -118,3 +138,66 @@ func DeleteSiteCascade(siteID uint) error {
 		return nil
 	})
 }
+
+// LoadFetchState 读取站点在指定定义版本下的抓取校验值，不存在时返回 nil。
+func LoadFetchState(siteID uint, definitionVersion int) (*FetchState, error) {
+	var states []FetchState
+	if err := DB.Where("site_id = ? AND definition_version = ?", siteID, definitionVersion).Limit(1).Find(&states).Error; err != nil {
+		return nil, fmt.Errorf("读取抓取状态失败: %w", err)
+	}
+	if len(states) == 0 {
+		return nil, nil
+	}
+	return &states[0], nil
+}
+
+// SaveFetchState 保存站点的抓取校验值；两者均为空时删除记录。
+func SaveFetchState(siteID uint, definitionVersion int, etag, lastModified string) error {
+	if etag == "" && lastModified == "" {
+		if err := DB.Where("site_id = ?", siteID).Delete(&FetchState{}).Error; err != nil {
+			return fmt.Errorf("删除抓取状态失败: %w", err)
+		}
+		return nil
+	}
+	state := FetchState{SiteID: siteID}
+	err := DB.Where("site_id = ?", siteID).
+		Assign(FetchState{DefinitionVersion: definitionVersion, ETag: etag, LastModified: lastModified}).
+		FirstOrCreate(&state).Error
+	if err != nil {
+		return fmt.Errorf("保存抓取状态失败: %w", err)
+	}
+	return nil
+}
+
+// LoadSiteSession 读取站点的登录会话，不存在时返回 nil。
+func LoadSiteSession(siteID uint) (*SiteSession, error) {
+	var sessions []SiteSession
+	if err := DB.Where("site_id = ?", siteID).Limit(1).Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("读取登录会话失败: %w", err)
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return &sessions[0], nil
+}
+
+// SaveSiteSession 保存站点登录会话的 Cookie；loggedInAt 为零值时保留原登录时间。
+func SaveSiteSession(siteID uint, fingerprint, cookies string, loggedInAt time.Time) error {
+	values := SiteSession{Fingerprint: fingerprint, Cookies: cookies}
+	if !loggedInAt.IsZero() {
+		values.LoggedInAt = loggedInAt
+	}
+	session := SiteSession{SiteID: siteID}
+	if err := DB.Where("site_id = ?", siteID).Assign(values).FirstOrCreate(&session).Error; err != nil {
+		return fmt.Errorf("保存登录会话失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteSiteSession 删除站点登录会话，下次检查时重新登录。
+func DeleteSiteSession(siteID uint) error {
+	if err := DB.Where("site_id = ?", siteID).Delete(&SiteSession{}).Error; err != nil {
+		return fmt.Errorf("删除登录会话失败: %w", err)
+	}
+	return nil
+}
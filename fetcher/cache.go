@@ -0,0 +1,175 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL 响应缓存默认有效期，足够覆盖同一轮调度中先后检查同一页面的监控
+	DefaultCacheTTL = 30 * time.Second
+
+	maxCacheEntries = 256
+)
+
+// CacheStats 响应缓存命中统计，自进程启动起累计。
+type CacheStats struct {
+	// Hits 直接使用缓存或与进行中的下载共享到成功结果的请求数
+	Hits uint64 `json:"hits"`
+	// Misses 实际发起下载的请求数
+	Misses uint64 `json:"misses"`
+}
+
+var (
+	fetchCache  atomic.Pointer[responseCache]
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+)
+
+func init() {
+	SetCacheTTL(DefaultCacheTTL)
+}
+
+// SetCacheTTL 替换全局响应缓存并清空已缓存的响应，0 表示关闭缓存。
+func SetCacheTTL(ttl time.Duration) {
+	fetchCache.Store(&responseCache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+		flights: make(map[string]*cacheFlight),
+	})
+}
+
+// CurrentCacheStats 返回响应缓存的命中统计。
+func CurrentCacheStats() CacheStats {
+	return CacheStats{Hits: cacheHits.Load(), Misses: cacheMisses.Load()}
+}
+
+// responseCache 进程内短时响应缓存：相同地址和相同有效抓取配置的 GET 请求共享一次下载。
+// 并发的相同请求等待同一次下载；下载完成后 200 响应在有效期内直接复用。
+type responseCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*cacheEntry  // 请求摘要 -> 已完成的 200 响应
+	flights map[string]*cacheFlight // 请求摘要和条件请求校验值 -> 进行中的下载
+}
+
+type cacheEntry struct {
+	resp    *Response
+	expires time.Time
+}
+
+type cacheFlight struct {
+	done chan struct{}
+	resp *Response
+	err  error
+}
+
+// do 经缓存执行请求。携带登录会话 Cookie 容器的请求与站点会话绑定，不参与共享。
+func (c *responseCache) do(ctx context.Context, f *Fetcher, r Request) (*Response, error) {
+	if c.ttl <= 0 || r.Jar != nil || (r.Method != "" && r.Method != http.MethodGet) {
+		return f.fetch(ctx, r)
+	}
+	key := f.cacheKey(r)
+	// 条件请求可能得到 304，只能与校验值相同的请求共享
+	flightKey := key + "\x00" + r.IfNoneMatch + "\x00" + r.IfModifiedSince
+
+	now := time.Now()
+	c.mu.Lock()
+	for k, entry := range c.entries {
+		if !entry.expires.After(now) {
+			delete(c.entries, k)
+		}
+	}
+	if entry, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		cacheHits.Add(1)
+		return entry.serve(r), nil
+	}
+	if flight, ok := c.flights[flightKey]; ok {
+		c.mu.Unlock()
+		select {
+		case <-flight.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		// 发起下载的检查被取消时，等待方自行下载
+		if flight.err != nil && ctx.Err() == nil &&
+			(errors.Is(flight.err, context.Canceled) || errors.Is(flight.err, context.DeadlineExceeded)) {
+			cacheMisses.Add(1)
+			return f.fetch(ctx, r)
+		}
+		if flight.err != nil {
+			return nil, flight.err
+		}
+		// 只有共享到成功的下载结果才计为命中
+		cacheHits.Add(1)
+		return copyResponse(flight.resp), nil
+	}
+	flight := &cacheFlight{done: make(chan struct{})}
+	c.flights[flightKey] = flight
+	c.mu.Unlock()
+	cacheMisses.Add(1)
+
+	flight.resp, flight.err = f.fetch(ctx, r)
+
+	c.mu.Lock()
+	delete(c.flights, flightKey)
+	if flight.err == nil && flight.resp.StatusCode == http.StatusOK && len(c.entries) < maxCacheEntries {
+		c.entries[key] = &cacheEntry{resp: flight.resp, expires: time.Now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	close(flight.done)
+
+	if flight.err != nil {
+		return nil, flight.err
+	}
+	return copyResponse(flight.resp), nil
+}
+
+// serve 返回缓存响应的副本；请求携带的 ETag 与缓存一致时按条件请求语义返回 304。
+func (e *cacheEntry) serve(r Request) *Response {
+	if r.IfNoneMatch != "" && r.IfNoneMatch == e.resp.Header.Get("ETag") {
+		return &Response{StatusCode: http.StatusNotModified, Header: e.resp.Header.Clone(), URL: e.resp.URL}
+	}
+	return copyResponse(e.resp)
+}
+
+func copyResponse(resp *Response) *Response {
+	copied := *resp
+	copied.Header = resp.Header.Clone()
+	return &copied
+}
+
+// cacheKey 按影响响应内容的全部请求参数计算摘要，条件请求校验值除外。
+func (f *Fetcher) cacheKey(r Request) string {
+	cookies := make([]string, 0, len(r.Cookies))
+	for _, cookie := range r.Cookies {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+	sort.Strings(cookies)
+	data, _ := json.Marshal(struct {
+		UserAgent string
+		Timeout   time.Duration
+		URL       string
+		Header    http.Header
+		Cookies   []string
+		Body      string
+		Charset   string
+		Proxy     string
+		ProxyPool string
+		TLS       *TLSOptions
+	}{
+		f.config.userAgent, f.config.client.Timeout, r.URL, r.Header, cookies,
+		r.Body, r.Charset, r.Proxy, r.ProxyPool, r.TLS,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,95 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheDeduplicatesDownloads(t *testing.T) {
+	SetCacheTTL(time.Minute)
+	t.Cleanup(func() { SetCacheTTL(0) })
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		if r.URL.Path == "/slow" || r.URL.Path == "/broken" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		if r.URL.Path == "/broken" {
+			// 声明的长度大于实际内容，读取响应体失败
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte("partial"))
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`<ul><li><a href="/a">公告 A</a></li></ul>`))
+	}))
+	defer server.Close()
+
+	f := New()
+	before := CurrentCacheStats()
+	for i := 0; i < 2; i++ {
+		if _, err := f.Do(context.Background(), Request{URL: server.URL + "/list"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Fatalf("repeated requests should share one download, got %d", got)
+	}
+	after := CurrentCacheStats()
+	if after.Hits-before.Hits != 1 || after.Misses-before.Misses != 1 {
+		t.Fatalf("unexpected cache stats: %+v -> %+v", before, after)
+	}
+
+	// 并发的相同请求等待同一次下载
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.Do(context.Background(), Request{URL: server.URL + "/slow"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := downloads.Load(); got != 2 {
+		t.Fatalf("concurrent requests should share one download, got %d downloads in total", got)
+	}
+
+	// 共享到失败的下载不计为命中
+	before = CurrentCacheStats()
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.Do(context.Background(), Request{URL: server.URL + "/broken"}); err == nil {
+				t.Error("expected a truncated download to fail")
+			}
+		}()
+	}
+	wg.Wait()
+	after = CurrentCacheStats()
+	if after.Hits != before.Hits || downloads.Load() != 3 {
+		t.Fatalf("failed shared download must not count as hits: %+v -> %+v, %d downloads", before, after, downloads.Load())
+	}
+
+	// 有效抓取配置不同的请求不共享
+	differentHeader := Request{URL: server.URL + "/list", Header: http.Header{"Accept-Language": {"en"}}}
+	if _, err := f.Do(context.Background(), differentHeader); err != nil {
+		t.Fatal(err)
+	}
+	if got := downloads.Load(); got != 4 {
+		t.Fatalf("requests with different headers must not share the cache, got %d downloads", got)
+	}
+
+	resp, err := f.Do(context.Background(), Request{URL: server.URL + "/list", IfNoneMatch: `"v1"`})
+	if err != nil || !resp.NotModified() || downloads.Load() != 4 {
+		t.Fatalf("matching ETag should be answered from cache with 304, got %+v (%v)", resp, err)
+	}
+}
@@ -0,0 +1,45 @@
+package fetcher
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// LookupCharset 校验字符集名称并返回规范名称，如 gb2312 → gbk。
+func LookupCharset(name string) (string, error) {
+	enc, canonical := charset.Lookup(strings.TrimSpace(name))
+	if enc == nil {
+		return "", fmt.Errorf("不支持的字符集: %s", name)
+	}
+	return canonical, nil
+}
+
+// decodeBody 将响应体转码为 UTF-8。
+// override 非空时强制使用指定字符集；否则依次参考 BOM、Content-Type 和 <meta charset>。
+func decodeBody(data []byte, contentType, override string) (string, error) {
+	if override != "" {
+		enc, _ := charset.Lookup(override)
+		if enc == nil {
+			return "", fmt.Errorf("不支持的字符集: %s", override)
+		}
+		decoded, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", fmt.Errorf("按 %s 转码失败: %w", override, err)
+		}
+		return string(decoded), nil
+	}
+
+	enc, name, certain := charset.DetermineEncoding(data, contentType)
+	// 没有任何编码声明时 DetermineEncoding 回退到 windows-1252，
+	// 这会破坏未声明编码的中文页面，因此保持原始字节不变。
+	if !certain && name == "windows-1252" {
+		return string(data), nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("按 %s 转码失败: %w", name, err)
+	}
+	return string(decoded), nil
+}
@@ -21,10 +21,8 @@ func newDefaultConfig() *config {
 	return &config{
 		client: &http.Client{
 			Timeout: defaultTimeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 20,
-			},
+			// 拨号时拦截内网地址，覆盖重定向和 DNS 变化
+			Transport: NewTransport(),
 		},
 		userAgent: defaultUserAgent,
 	}
@@ -0,0 +1,123 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 抓取错误分类，用于区分站点故障、被目标站点拦截和本地策略拒绝。
+const (
+	ErrorClassDNS        = "dns"        // 域名解析失败
+	ErrorClassTimeout    = "timeout"    // 连接或读取超时
+	ErrorClassConnection = "connection" // 连接被拒绝、重置或意外断开
+	ErrorClassHTTP4xx    = "http_4xx"   // 其他客户端错误（404、410 等）
+	ErrorClassHTTP5xx    = "http_5xx"   // 服务端错误
+	ErrorClassBlocked    = "blocked"    // 目标站点拒绝访问（401、403、429）或返回验证页
+	ErrorClassPolicy     = "policy"     // 内网拦截或 robots.txt 禁止，请求未发出
+	ErrorClassCanceled   = "canceled"   // 检查被取消
+	ErrorClassOther      = "other"      // 其他错误（如解析失败）
+)
+
+// ErrChallengePage 响应是反爬验证页或登录墙，而不是目标页面。
+var ErrChallengePage = errors.New("疑似被拦截（验证页或登录墙）")
+
+// StatusError 服务端返回了非预期的状态码。
+type StatusError struct {
+	StatusCode int
+	// RetryAfter 为响应 Retry-After 头给出的等待时间，未提供时为 0
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// parseRetryAfter 解析秒数或 HTTP 日期格式的 Retry-After。
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// ClassifyError 返回错误的分类，err 为 nil 时返回空字符串。
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch code := statusErr.StatusCode; {
+		case code == http.StatusUnauthorized || code == http.StatusForbidden || code == http.StatusTooManyRequests:
+			return ErrorClassBlocked
+		case code >= 500:
+			return ErrorClassHTTP5xx
+		default:
+			return ErrorClassHTTP4xx
+		}
+	}
+	if errors.Is(err, ErrChallengePage) {
+		return ErrorClassBlocked
+	}
+	if errors.Is(err, ErrBlockedAddress) || errors.Is(err, ErrRobotsDisallowed) {
+		return ErrorClassPolicy
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && !dnsErr.IsTimeout {
+		return ErrorClassDNS
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return ErrorClassConnection
+	}
+	return ErrorClassOther
+}
+
+// Retryable 报告错误是否可能是暂时性的：超时、连接中断、429 和 5xx。
+// 404、410 等其他 4xx 以及被策略拒绝的请求重试也不会成功。
+func Retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	switch ClassifyError(err) {
+	case ErrorClassTimeout, ErrorClassConnection:
+		return true
+	}
+	return false
+}
+
+// RetryAfter 返回错误携带的 Retry-After 等待时间。
+func RetryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
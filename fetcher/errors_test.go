@@ -0,0 +1,79 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchErrorsAreClassified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/unavailable":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/missing":
+			w.WriteHeader(http.StatusGone)
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		path      string
+		class     string
+		retryable bool
+	}{
+		{"/unavailable", ErrorClassHTTP5xx, true},
+		{"/missing", ErrorClassHTTP4xx, false},
+		{"/limited", ErrorClassBlocked, true},
+		{"/forbidden", ErrorClassBlocked, false},
+	}
+	f := New()
+	for _, tc := range cases {
+		_, err := f.Do(context.Background(), Request{URL: server.URL + tc.path})
+		if got := ClassifyError(err); got != tc.class {
+			t.Errorf("%s: class = %q, want %q (%v)", tc.path, got, tc.class, err)
+		}
+		if got := Retryable(err); got != tc.retryable {
+			t.Errorf("%s: retryable = %v, want %v", tc.path, got, tc.retryable)
+		}
+	}
+	_, err := f.Do(context.Background(), Request{URL: server.URL + "/unavailable"})
+	if got := RetryAfter(err); got != 2*time.Minute {
+		t.Errorf("Retry-After should be carried by the status error, got %v", got)
+	}
+
+	if _, err := f.Do(context.Background(), Request{URL: "http://gentry-nonexistent.invalid/"}); ClassifyError(err) != ErrorClassDNS {
+		t.Fatalf("unresolvable host should be classified as dns, got %q (%v)", ClassifyError(err), err)
+	}
+	if got := ClassifyError(fmt.Errorf("第 2 页%w", ErrChallengePage)); got != ErrorClassBlocked {
+		t.Errorf("wrapped challenge pages should be blocked, got %q", got)
+	}
+	if got := ClassifyError(context.Canceled); got != ErrorClassCanceled {
+		t.Errorf("canceled checks should be classified as canceled, got %q", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-5":                            0,
+		"Sat, 17 Oct 2026 08:01:00 GMT": time.Minute,
+		"Sat, 17 Oct 2026 07:00:00 GMT": 0,
+		"soon":                          0,
+	}
+	for value, want := range cases {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -5,12 +5,50 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type Fetcher struct {
 	config *config // 持有私有配置的不可变副本
 }
 
+// Request 描述一次抓取请求，零值字段使用默认行为（GET、默认 User-Agent）。
+type Request struct {
+	Method  string
+	URL     string
+	Header  http.Header
+	Cookies []*http.Cookie
+	Body    string
+	// Charset 强制指定响应字符集，为空时自动识别
+	Charset string
+	// IfNoneMatch/IfModifiedSince 为上次响应的校验值，非空时发起条件请求
+	IfNoneMatch     string
+	IfModifiedSince string
+	// Proxy 代理地址，ProxyDirect 表示直连；ProxyPool 为命名代理池，二者都为空时使用全局默认代理
+	Proxy     string
+	ProxyPool string
+	// TLS 站点级 TLS 设置，为空时使用默认配置
+	TLS *TLSOptions
+	// Jar 登录会话的 Cookie 容器，重定向过程中设置的 Cookie 同样会被保存
+	Jar http.CookieJar
+}
+
+// Response 抓取成功后的响应内容，Body 已转码为 UTF-8。
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+	// URL 跟随重定向后的最终地址，非 HTTP 来源为空
+	URL string
+}
+
+// NotModified 报告条件请求是否命中 304，此时 Body 为空。
+func (r *Response) NotModified() bool {
+	return r.StatusCode == http.StatusNotModified
+}
+
 // New 创建Fetcher实例（线程安全）
 func New(opts ...Option) *Fetcher {
 	cfg := newDefaultConfig() // 深拷贝默认配置
@@ -27,36 +65,140 @@ func (f *Fetcher) Fetch(url string) (string, error) {
 
 // FetchContext 执行支持取消和超时传递的 HTTP 请求。
 func (f *Fetcher) FetchContext(ctx context.Context, url string) (string, error) {
+	resp, err := f.Do(ctx, Request{URL: url})
+	if err != nil {
+		return "", err
+	}
+	return resp.Body, nil
+}
+
+// Do 按 Request 描述执行请求，支持自定义方法、请求头、Cookie 和请求体。
+// 相同的 GET 请求在缓存有效期内共享一次下载。
+func (f *Fetcher) Do(ctx context.Context, r Request) (*Response, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	return fetchCache.Load().do(ctx, f, r)
+}
+
+// fetch 实际发起请求。
+func (f *Fetcher) fetch(ctx context.Context, r Request) (*Response, error) {
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	client, err := f.clientFor(r.TLS)
+	if err != nil {
+		return nil, err
+	}
+	proxyURL, reportProxy, err := resolveProxy(r)
 	if err != nil {
-		return "", fmt.Errorf("创建请求失败: %w", err)
+		return nil, err
+	}
+	ctx = withProxy(ctx, proxyURL)
+	if r.Jar != nil || proxyURL != nil {
+		custom := *client
+		if r.Jar != nil {
+			custom.Jar = r.Jar
+		}
+		// 经代理时目标主机由代理解析，重定向目标需逐跳检查
+		if proxyURL != nil {
+			custom.CheckRedirect = guardProxiedRedirect
+		}
+		client = &custom
 	}
 
-	// 设置User-Agent（唯一直接管理的Header）
+	var body io.Reader
+	if r.Body != "" {
+		body = strings.NewReader(r.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	if proxyURL != nil {
+		if err := checkProxiedTarget(ctx, req.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	// 默认 User-Agent，可被站点请求头覆盖
 	req.Header.Set("User-Agent", f.config.userAgent)
+	for key, values := range r.Header {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	for _, cookie := range r.Cookies {
+		req.AddCookie(cookie)
+	}
+	conditional := r.IfNoneMatch != "" || r.IfModifiedSince != ""
+	if r.IfNoneMatch != "" {
+		req.Header.Set("If-None-Match", r.IfNoneMatch)
+	}
+	if r.IfModifiedSince != "" {
+		req.Header.Set("If-Modified-Since", r.IfModifiedSince)
+	}
+
+	// 按主机排队，遵守请求间隔、并发上限和 robots.txt
+	release, err := f.schedule(ctx, client, req.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	// 执行请求（所有网络行为委托给http.Client）
-	resp, err := f.config.client.Do(req)
+	resp, err := client.Do(req)
+	reportProxy(err)
 	if err != nil {
-		return "", fmt.Errorf("请求失败: %w", err)
+		if proxyURL != nil {
+			return nil, fmt.Errorf("请求失败（代理 %s）: %w", proxyURL.Redacted(), err)
+		}
+		return nil, fmt.Errorf("请求失败: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// 检查状态码
+	// 检查状态码，仅条件请求接受 304
+	if conditional && resp.StatusCode == http.StatusNotModified {
+		return &Response{StatusCode: resp.StatusCode, Header: resp.Header, URL: resp.Request.URL.String()}, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 
 	// 读取响应（限制10MB内存）
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
 	if err != nil {
-		return "", fmt.Errorf("读取失败: %w", err)
+		return nil, fmt.Errorf("读取失败: %w", err)
+	}
+	text, err := decodeBody(data, resp.Header.Get("Content-Type"), r.Charset)
+	if err != nil {
+		return nil, err
 	}
 
-	return string(body), nil
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: text, URL: resp.Request.URL.String()}, nil
+}
+
+// schedule 在主机调度器中等待发起请求的时机，返回的函数在响应读取完毕后调用。
+func (f *Fetcher) schedule(ctx context.Context, client *http.Client, target *url.URL) (func(), error) {
+	s := hostScheduler.Load()
+	var delay time.Duration
+	if s.config.RespectRobots {
+		rules := s.robotsFor(ctx, client, f.config.userAgent, target)
+		if !rules.allowed(target) {
+			return nil, fmt.Errorf("%w: %s", ErrRobotsDisallowed, target.Redacted())
+		}
+		delay = rules.crawlDelay
+	}
+	release, err := s.acquire(ctx, strings.ToLower(target.Host), delay)
+	if err != nil {
+		return nil, fmt.Errorf("等待请求时机失败: %w", err)
+	}
+	return release, nil
 }
 
 /*
@@ -77,4 +219,18 @@ func (f *Fetcher) FetchContext(ctx context.Context, url string) (string, error)
 	f3 := fetcher.New(fetcher.WithClient(customClient))
 	result, _ = f3.Fetch("https://internal.example.com")
 
+	// 自定义请求方法、请求头和请求体
+	resp, _ := f1.Do(ctx, fetcher.Request{
+		Method: "POST",
+		URL:    "https://example.com/search",
+		Header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:   "q=公告",
+	})
+
+	// 条件请求，页面未变化时返回 304
+	resp, _ = f1.Do(ctx, fetcher.Request{URL: "https://example.com", IfNoneMatch: resp.Header.Get("ETag")})
+	if resp.NotModified() {
+		// 跳过解析
+	}
+
 */
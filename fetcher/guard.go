@@ -0,0 +1,132 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress 连接目标属于回环、链路本地或内网地址且不在白名单内。
+var ErrBlockedAddress = errors.New("目标地址不允许访问")
+
+// allowedNetworks 允许访问的内网网段，供内网监控使用。
+var allowedNetworks atomic.Pointer[[]netip.Prefix]
+
+// SetAllowedNetworks 设置允许访问的内网网段，传入 nil 恢复默认拦截策略。
+func SetAllowedNetworks(prefixes []netip.Prefix) {
+	copied := append([]netip.Prefix(nil), prefixes...)
+	allowedNetworks.Store(&copied)
+}
+
+// ParseAllowedNetworks 解析逗号或空白分隔的 CIDR 列表，单个 IP 视为单地址网段。
+func ParseAllowedNetworks(raw string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' }) {
+		if addr, err := netip.ParseAddr(item); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("无效的网段: %s", item)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// IsBlockedIP 报告地址是否应被拦截：回环、内网、链路本地、组播和未指定地址，
+// 白名单网段内的地址除外。
+func IsBlockedIP(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if allowed := allowedNetworks.Load(); allowed != nil {
+		for _, prefix := range *allowed {
+			if prefix.Contains(addr) {
+				return false
+			}
+		}
+	}
+	return addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified()
+}
+
+// guardControl 在建立连接前检查 DNS 解析后的实际地址，
+// 因此重定向目标和 DNS 变化同样受到约束。
+func guardControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if IsBlockedIP(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// maxRedirects 与 http.Client 默认策略一致的重定向次数上限
+const maxRedirects = 10
+
+// checkProxiedTarget 经代理的请求由代理解析目标主机，拨号拦截只能检查代理地址，
+// 因此发出请求前先在本地解析目标主机并检查。本地无法解析的主机交给代理处理。
+func checkProxiedTarget(ctx context.Context, target *url.URL) error {
+	host := target.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if IsBlockedIP(addr) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if IsBlockedIP(addr) {
+			return fmt.Errorf("%w: %s (%s)", ErrBlockedAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// guardProxiedRedirect 经代理请求的重定向目标同样由代理解析，逐跳检查目标地址。
+func guardProxiedRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("重定向次数超过 %d 次", maxRedirects)
+	}
+	return checkProxiedTarget(req.Context(), req.URL)
+}
+
+// NewTransport 创建在拨号时执行地址拦截策略的 Transport。
+func NewTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   guardControl,
+	}
+	return &http.Transport{
+		// 代理地址同样经过拨号拦截，内网代理需加入白名单；目标地址由 Fetcher 在请求前检查
+		Proxy:               proxyFromContext,
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+	}
+}
+
+// NewClient 创建受地址拦截策略保护的 http.Client，供通知等出站请求使用。
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: NewTransport()}
+}
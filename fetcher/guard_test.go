@@ -0,0 +1,81 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestFetcherBlocksPrivateAddressesAtDialTime(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><h1>internal</h1></body></html>`))
+	}))
+	defer internal.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer redirect.Close()
+
+	SetAllowedNetworks(nil)
+	defer SetAllowedNetworks(loopbackNetworks)
+
+	f := New()
+	for _, target := range []string{internal.URL, redirect.URL} {
+		if _, err := f.Do(context.Background(), Request{URL: target}); !errors.Is(err, ErrBlockedAddress) {
+			t.Fatalf("loopback fetch %s must be blocked, got %v", target, err)
+		}
+	}
+
+	allowed, err := ParseAllowedNetworks("10.0.0.0/8, 127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetAllowedNetworks(allowed)
+	if _, err := f.Do(context.Background(), Request{URL: redirect.URL}); err != nil {
+		t.Fatalf("allowlisted loopback fetch failed: %v", err)
+	}
+	if !IsBlockedIP(netip.MustParseAddr("192.168.1.1")) || IsBlockedIP(netip.MustParseAddr("10.1.2.3")) {
+		t.Fatal("allowlist must only exempt configured networks")
+	}
+	if _, err := ParseAllowedNetworks("intranet"); err == nil {
+		t.Fatal("invalid networks must be rejected")
+	}
+}
+
+func TestProxiedRequestsCheckTargetAddress(t *testing.T) {
+	var hits []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.String())
+		if r.URL.Host == "redirect.example" {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+			return
+		}
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	// 只放行代理本身，目标地址仍按默认策略拦截
+	SetAllowedNetworks([]netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")})
+	defer SetAllowedNetworks(loopbackNetworks)
+
+	f := New()
+	for _, target := range []string{"http://169.254.169.254/latest/meta-data/", "http://10.0.0.1/", "http://[::1]/"} {
+		if _, err := f.Do(context.Background(), Request{URL: target, Proxy: proxy.URL}); !errors.Is(err, ErrBlockedAddress) {
+			t.Fatalf("proxied fetch of %s must be blocked, got %v", target, err)
+		}
+	}
+	if len(hits) != 0 {
+		t.Fatalf("blocked targets must not reach the proxy, got %v", hits)
+	}
+	if _, err := f.Do(context.Background(), Request{URL: "http://redirect.example/", Proxy: proxy.URL}); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("proxied redirect to a link-local address must be blocked, got %v", err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("only the first hop should reach the proxy, got %v", hits)
+	}
+	resp, err := f.Do(context.Background(), Request{URL: "http://public.example/", Proxy: proxy.URL})
+	if err != nil || resp.Body != "via proxy" {
+		t.Fatalf("public targets should still go through the proxy, got %+v (%v)", resp, err)
+	}
+}
@@ -0,0 +1,21 @@
+package fetcher
+
+import (
+	"net/netip"
+	"os"
+	"testing"
+)
+
+// loopbackNetworks 测试服务器使用 httptest 回环地址，需像内网监控部署一样显式放行
+var loopbackNetworks = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}
+
+// 同主机请求间隔和响应缓存在各自测试中按需开启，避免拖慢或干扰其他测试。
+func TestMain(m *testing.M) {
+	SetAllowedNetworks(loopbackNetworks)
+	SetPoliteness(Politeness{})
+	SetCacheTTL(0)
+	os.Exit(m.Run())
+}
@@ -27,7 +27,7 @@ func WithUserAgent(ua string) Option {
 	}
 }
 
-// WithClient 完全自定义http.Client
+// WithClient 完全自定义http.Client；自定义 Transport 不受内网地址拦截保护
 func WithClient(cli *http.Client) Option {
 	return func(c *config) {
 		if cli == nil {
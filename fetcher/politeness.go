@@ -0,0 +1,343 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRobotsDisallowed 目标路径被站点 robots.txt 禁止抓取。
+var ErrRobotsDisallowed = errors.New("robots.txt 禁止抓取")
+
+const (
+	// RobotsUserAgent 匹配 robots.txt 分组时使用的产品标识
+	RobotsUserAgent = "Gentry"
+
+	robotsTTL           = 24 * time.Hour
+	robotsErrorTTL      = time.Hour
+	maxRobotsSize       = 512 << 10
+	maxRobotsCrawlDelay = time.Minute
+
+	// gateSweepInterval 清理空闲主机状态的最小间隔
+	gateSweepInterval = 10 * time.Minute
+)
+
+// Politeness 按主机生效的访问约束，所有经 Fetcher 发出的请求共享。
+type Politeness struct {
+	// MinInterval 同一主机两次请求开始时间的最小间隔，0 表示不限制
+	MinInterval time.Duration
+	// MaxConcurrent 同一主机同时进行的请求数上限，0 表示不限制
+	MaxConcurrent int
+	// RespectRobots 遵守 robots.txt 的 Disallow 规则和 Crawl-delay
+	RespectRobots bool
+}
+
+// DefaultPoliteness 未显式配置时的默认约束。
+var DefaultPoliteness = Politeness{MinInterval: time.Second, MaxConcurrent: 2}
+
+var hostScheduler atomic.Pointer[scheduler]
+
+func init() {
+	SetPoliteness(DefaultPoliteness)
+}
+
+// SetPoliteness 替换全局主机访问约束，同时清空 robots.txt 缓存。
+// 替换前已开始的请求仍按旧约束释放。
+func SetPoliteness(p Politeness) {
+	hostScheduler.Store(&scheduler{config: p})
+}
+
+// CurrentPoliteness 返回当前生效的主机访问约束。
+func CurrentPoliteness() Politeness {
+	return hostScheduler.Load().config
+}
+
+// scheduler 按主机维护请求间隔、并发数和 robots.txt 缓存。
+type scheduler struct {
+	config    Politeness
+	hosts     sync.Map // host -> *hostGate
+	robots    sync.Map // scheme://host -> *robotsEntry
+	lastSweep atomic.Int64
+}
+
+type hostGate struct {
+	sem  chan struct{}
+	mu   sync.Mutex
+	next time.Time
+	// users 正在等待或持有名额的请求数；evicted 表示已被清理，持有者需重新获取
+	users   int
+	evicted bool
+}
+
+// gate 返回主机的访问状态并登记一个使用者，使用结束后须调用 leave。
+func (s *scheduler) gate(host string) *hostGate {
+	s.sweepIdleGates()
+	for {
+		value, ok := s.hosts.Load(host)
+		if !ok {
+			g := &hostGate{}
+			if s.config.MaxConcurrent > 0 {
+				g.sem = make(chan struct{}, s.config.MaxConcurrent)
+			}
+			value, _ = s.hosts.LoadOrStore(host, g)
+		}
+		g := value.(*hostGate)
+		g.mu.Lock()
+		if !g.evicted {
+			g.users++
+			g.mu.Unlock()
+			return g
+		}
+		g.mu.Unlock()
+	}
+}
+
+func (g *hostGate) leave() {
+	g.mu.Lock()
+	g.users--
+	g.mu.Unlock()
+}
+
+// sweepIdleGates 移除没有使用者且请求间隔已过的主机状态，避免访问过的主机无限累积。
+func (s *scheduler) sweepIdleGates() {
+	now := time.Now()
+	last := s.lastSweep.Load()
+	if now.UnixNano()-last < int64(gateSweepInterval) || !s.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	s.hosts.Range(func(key, value any) bool {
+		g := value.(*hostGate)
+		g.mu.Lock()
+		if g.users == 0 && !g.next.After(now) {
+			g.evicted = true
+			s.hosts.CompareAndDelete(key, g)
+		}
+		g.mu.Unlock()
+		return true
+	})
+}
+
+// acquire 等待主机的并发名额和请求间隔，返回的函数在请求结束后释放名额。
+// delay 为 robots.txt 要求的 Crawl-delay，与 MinInterval 取较大者。
+// 等待期间 ctx 结束时撤销本次预留的请求时间，不推迟后续请求。
+func (s *scheduler) acquire(ctx context.Context, host string, delay time.Duration) (func(), error) {
+	g := s.gate(host)
+	release := g.leave
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+			release = func() {
+				<-g.sem
+				g.leave()
+			}
+		case <-ctx.Done():
+			g.leave()
+			return nil, ctx.Err()
+		}
+	}
+
+	interval := max(s.config.MinInterval, delay)
+	if interval <= 0 {
+		return release, nil
+	}
+	g.mu.Lock()
+	previous := g.next
+	start := time.Now()
+	if g.next.After(start) {
+		start = g.next
+	}
+	reserved := start.Add(interval)
+	g.next = reserved
+	g.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return release, nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		// 之后没有其他请求预留时才能撤销，否则保持已排好的顺序
+		g.mu.Lock()
+		if g.next.Equal(reserved) {
+			g.next = previous
+		}
+		g.mu.Unlock()
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// robotsEntry 单个站点的 robots.txt 缓存，并发请求只触发一次抓取。
+type robotsEntry struct {
+	mu      sync.Mutex
+	rules   *robotsRules
+	expires time.Time
+}
+
+// robotsFor 返回目标站点适用于本程序的 robots.txt 规则。
+// robots.txt 不存在或无法读取时视为不限制，并在较短时间后重试。
+func (s *scheduler) robotsFor(ctx context.Context, client *http.Client, userAgent string, target *url.URL) *robotsRules {
+	origin := target.Scheme + "://" + target.Host
+	value, _ := s.robots.LoadOrStore(origin, &robotsEntry{})
+	entry := value.(*robotsEntry)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.rules != nil && time.Now().Before(entry.expires) {
+		return entry.rules
+	}
+	rules, err := fetchRobots(ctx, client, userAgent, origin+"/robots.txt")
+	if err != nil {
+		entry.rules, entry.expires = &robotsRules{}, time.Now().Add(robotsErrorTTL)
+		return entry.rules
+	}
+	entry.rules, entry.expires = rules, time.Now().Add(robotsTTL)
+	return rules
+}
+
+func fetchRobots(ctx context.Context, client *http.Client, userAgent, robotsURL string) (*robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return parseRobots(io.LimitReader(resp.Body, maxRobotsSize), RobotsUserAgent), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// 4xx 表示站点未提供 robots.txt，按惯例不限制
+		return &robotsRules{}, nil
+	default:
+		return nil, errors.New(resp.Status)
+	}
+}
+
+// robotsRules 适用于本程序的 robots.txt 规则组。
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// parseRobots 解析 robots.txt，优先使用与 agent 同名的分组，否则使用 "*" 分组。
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+	var (
+		named, wildcard robotsRules
+		hasNamed        bool
+		groupAgents     []string
+		inRules         bool
+	)
+	targets := func() []*robotsRules {
+		var out []*robotsRules
+		for _, a := range groupAgents {
+			switch a {
+			case agent:
+				out = append(out, &named)
+			case "*":
+				out = append(out, &wildcard)
+			}
+		}
+		return out
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if inRules {
+				groupAgents, inRules = nil, false
+			}
+			value = strings.ToLower(value)
+			hasNamed = hasNamed || value == agent
+			groupAgents = append(groupAgents, value)
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)}
+			for _, target := range targets() {
+				target.rules = append(target.rules, rule)
+			}
+		case "crawl-delay":
+			inRules = true
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds <= 0 {
+				continue
+			}
+			delay := min(time.Duration(seconds*float64(time.Second)), maxRobotsCrawlDelay)
+			for _, target := range targets() {
+				target.crawlDelay = delay
+			}
+		}
+	}
+	if hasNamed {
+		return &named
+	}
+	return &wildcard
+}
+
+// robotsPattern 把 robots.txt 路径规则转换为正则，支持 * 通配和 $ 结尾锚定。
+func robotsPattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed 按最长匹配判断路径是否允许抓取，长度相同时 Allow 优先。
+func (r *robotsRules) allowed(target *url.URL) bool {
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	allow, matched := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > matched || (rule.length == matched && rule.allow) {
+			allow, matched = rule.allow, rule.length
+		}
+	}
+	return allow
+}
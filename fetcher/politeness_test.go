@@ -0,0 +1,147 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostPolitenessSpacesAndCapsRequests(t *testing.T) {
+	SetPoliteness(Politeness{MinInterval: 40 * time.Millisecond, MaxConcurrent: 1})
+	t.Cleanup(func() { SetPoliteness(Politeness{}) })
+
+	var (
+		mu       sync.Mutex
+		starts   []time.Time
+		inFlight int32
+		peak     int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		if current > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, current)
+		}
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	f := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.Do(context.Background(), Request{URL: server.URL}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&peak); got != 1 {
+		t.Fatalf("per-host concurrency cap exceeded: %d", got)
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < 35*time.Millisecond {
+			t.Fatalf("requests %d and %d only %v apart", i-1, i, gap)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.Do(ctx, Request{URL: server.URL}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("waiting request should honour cancellation, got %v", err)
+	}
+}
+
+func TestHostPolitenessCancelledWaitReleasesReservation(t *testing.T) {
+	s := &scheduler{config: Politeness{MinInterval: 200 * time.Millisecond}}
+	release, err := s.acquire(context.Background(), "example.com", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx, "example.com", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to be cancelled, got %v", err)
+	}
+
+	start := time.Now()
+	release, err = s.acquire(context.Background(), "example.com", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if waited := time.Since(start); waited > 300*time.Millisecond {
+		t.Fatalf("cancelled request should not push back the next slot, waited %v", waited)
+	}
+}
+
+func TestHostPolitenessEvictsIdleHosts(t *testing.T) {
+	s := &scheduler{config: Politeness{MinInterval: time.Millisecond, MaxConcurrent: 1}}
+	release, err := s.acquire(context.Background(), "idle.example.com", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	busy, err := s.acquire(context.Background(), "busy.example.com", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy()
+	release()
+	time.Sleep(5 * time.Millisecond)
+
+	s.lastSweep.Store(0)
+	s.sweepIdleGates()
+	if _, ok := s.hosts.Load("idle.example.com"); ok {
+		t.Fatal("idle host gate should be evicted")
+	}
+	if _, ok := s.hosts.Load("busy.example.com"); !ok {
+		t.Fatal("host with an active request must be kept")
+	}
+}
+
+func TestHostPolitenessRespectsRobots(t *testing.T) {
+	SetPoliteness(Politeness{RespectRobots: true})
+	t.Cleanup(func() { SetPoliteness(Politeness{}) })
+
+	var robotsHits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&robotsHits, 1)
+			w.Write([]byte("User-agent: *\nDisallow: /\n\nUser-agent: Gentry\nDisallow: /private\nAllow: /private/open$\nCrawl-delay: 0.05\n"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	f := New()
+	if _, err := f.Do(context.Background(), Request{URL: server.URL + "/private/list"}); !errors.Is(err, ErrRobotsDisallowed) {
+		t.Fatalf("disallowed path should be refused, got %v", err)
+	}
+	start := time.Now()
+	for _, path := range []string{"/public", "/private/open"} {
+		if _, err := f.Do(context.Background(), Request{URL: server.URL + path}); err != nil {
+			t.Fatalf("%s should be allowed by the named group: %v", path, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Fatalf("crawl-delay not applied, two requests took %v", elapsed)
+	}
+	if got := atomic.LoadInt32(&robotsHits); got != 1 {
+		t.Fatalf("robots.txt should be cached, fetched %d times", got)
+	}
+}
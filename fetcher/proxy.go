@@ -0,0 +1,230 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// ProxyDirect 站点显式直连，不使用全局默认代理
+	ProxyDirect = "direct"
+
+	ProxyStrategyRoundRobin = "round_robin" // 依次轮换
+	ProxyStrategyFailover   = "failover"    // 优先使用第一个，失败后切换到下一个
+
+	// proxyCooldown 代理连接失败后暂停使用的时间
+	proxyCooldown = time.Minute
+)
+
+// ProxyPoolConfig 命名代理池。
+type ProxyPoolConfig struct {
+	Name     string   `json:"name"`
+	Strategy string   `json:"strategy,omitempty"`
+	Proxies  []string `json:"proxies"`
+}
+
+// ProxySettings 全局代理设置：默认代理（Proxy 与 ProxyPool 二选一）和可供站点引用的代理池。
+type ProxySettings struct {
+	Proxy     string            `json:"proxy,omitempty"`
+	ProxyPool string            `json:"proxy_pool,omitempty"`
+	Pools     []ProxyPoolConfig `json:"pools,omitempty"`
+}
+
+// ParseProxyURL 校验代理地址，支持 http、https、socks5 和 socks5h。
+func ParseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("代理地址无效: %s", RedactProxyURL(raw))
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("代理协议仅支持 http、https、socks5: %s", RedactProxyURL(raw))
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return nil, fmt.Errorf("代理地址不能包含路径或参数: %s", RedactProxyURL(raw))
+	}
+	return u, nil
+}
+
+// RedactProxyURL 隐藏代理地址中的密码，用于日志和错误信息。
+func RedactProxyURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "[无效地址]"
+	}
+	return u.Redacted()
+}
+
+// Normalize 校验并规范化代理设置。
+func (s *ProxySettings) Normalize() error {
+	s.Proxy = strings.TrimSpace(s.Proxy)
+	s.ProxyPool = strings.TrimSpace(s.ProxyPool)
+	if s.Proxy != "" && s.ProxyPool != "" {
+		return fmt.Errorf("默认代理和默认代理池只能配置一个")
+	}
+	if s.Proxy != "" {
+		if _, err := ParseProxyURL(s.Proxy); err != nil {
+			return err
+		}
+	}
+	names := make(map[string]bool, len(s.Pools))
+	for i := range s.Pools {
+		pool := &s.Pools[i]
+		pool.Name = strings.TrimSpace(pool.Name)
+		if pool.Name == "" {
+			return fmt.Errorf("代理池名称不能为空")
+		}
+		if names[pool.Name] {
+			return fmt.Errorf("代理池名称重复: %s", pool.Name)
+		}
+		names[pool.Name] = true
+		switch pool.Strategy {
+		case "":
+			pool.Strategy = ProxyStrategyRoundRobin
+		case ProxyStrategyRoundRobin, ProxyStrategyFailover:
+		default:
+			return fmt.Errorf("代理池 %s 的选择策略仅支持 round_robin 或 failover", pool.Name)
+		}
+		if len(pool.Proxies) == 0 {
+			return fmt.Errorf("代理池 %s 至少需要一个代理", pool.Name)
+		}
+		for j, proxy := range pool.Proxies {
+			if _, err := ParseProxyURL(proxy); err != nil {
+				return fmt.Errorf("代理池 %s: %w", pool.Name, err)
+			}
+			pool.Proxies[j] = strings.TrimSpace(proxy)
+		}
+	}
+	if s.ProxyPool != "" && !names[s.ProxyPool] {
+		return fmt.Errorf("默认代理池不存在: %s", s.ProxyPool)
+	}
+	return nil
+}
+
+// proxyRegistry 当前生效的代理设置。
+type proxyRegistry struct {
+	defaultProxy *url.URL
+	defaultPool  string
+	pools        map[string]*proxyPool
+}
+
+var proxies atomic.Pointer[proxyRegistry]
+
+func init() {
+	proxies.Store(&proxyRegistry{})
+}
+
+// SetProxySettings 校验并替换全局代理设置。
+func SetProxySettings(settings ProxySettings) error {
+	if err := settings.Normalize(); err != nil {
+		return err
+	}
+	registry := &proxyRegistry{defaultPool: settings.ProxyPool, pools: make(map[string]*proxyPool, len(settings.Pools))}
+	if settings.Proxy != "" {
+		registry.defaultProxy, _ = ParseProxyURL(settings.Proxy)
+	}
+	for _, config := range settings.Pools {
+		pool := &proxyPool{name: config.Name, strategy: config.Strategy, downUntil: make([]time.Time, len(config.Proxies))}
+		for _, raw := range config.Proxies {
+			u, _ := ParseProxyURL(raw)
+			pool.proxies = append(pool.proxies, u)
+		}
+		registry.pools[config.Name] = pool
+	}
+	proxies.Store(registry)
+	return nil
+}
+
+// proxyPool 代理池，连接失败的代理暂停使用 proxyCooldown 后再参与选择。
+type proxyPool struct {
+	name      string
+	strategy  string
+	proxies   []*url.URL
+	next      atomic.Uint64
+	mu        sync.Mutex
+	downUntil []time.Time
+}
+
+// pick 选择一个代理；全部处于暂停期时仍按策略返回，避免请求直接失败。
+func (p *proxyPool) pick() (int, *url.URL) {
+	n := len(p.proxies)
+	start := 0
+	if p.strategy == ProxyStrategyRoundRobin {
+		start = int(p.next.Add(1)-1) % n
+	}
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for offset := 0; offset < n; offset++ {
+		i := (start + offset) % n
+		if !p.downUntil[i].After(now) {
+			return i, p.proxies[i]
+		}
+	}
+	return start, p.proxies[start]
+}
+
+// report 记录代理的使用结果：连接失败或超时时暂停使用，成功时恢复。
+func (p *proxyPool) report(i int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch ClassifyError(err) {
+	case "":
+		p.downUntil[i] = time.Time{}
+	case ErrorClassConnection, ErrorClassTimeout, ErrorClassDNS:
+		p.downUntil[i] = time.Now().Add(proxyCooldown)
+	}
+}
+
+type proxyContextKey struct{}
+
+// resolveProxy 按请求、站点代理池和全局默认值确定本次请求使用的代理。
+// 返回的 report 在请求结束后调用，用于代理池故障切换。
+func resolveProxy(r Request) (*url.URL, func(error), error) {
+	noop := func(error) {}
+	registry := proxies.Load()
+	switch {
+	case r.Proxy == ProxyDirect:
+		return nil, noop, nil
+	case r.Proxy != "":
+		u, err := ParseProxyURL(r.Proxy)
+		return u, noop, err
+	case r.ProxyPool != "":
+		return registry.pickFromPool(r.ProxyPool)
+	case registry.defaultPool != "":
+		return registry.pickFromPool(registry.defaultPool)
+	}
+	return registry.defaultProxy, noop, nil
+}
+
+func (r *proxyRegistry) pickFromPool(name string) (*url.URL, func(error), error) {
+	pool, ok := r.pools[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("代理池不存在: %s", name)
+	}
+	i, u := pool.pick()
+	return u, func(err error) { pool.report(i, err) }, nil
+}
+
+// proxyFromContext 供 Transport 使用，代理由 Fetcher.Do 写入请求上下文。
+// 未经 Fetcher 的请求（如通知推送）始终直连。
+func proxyFromContext(req *http.Request) (*url.URL, error) {
+	if u, ok := req.Context().Value(proxyContextKey{}).(*url.URL); ok {
+		return u, nil
+	}
+	return nil, nil
+}
+
+func withProxy(ctx context.Context, u *url.URL) context.Context {
+	if u == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, proxyContextKey{}, u)
+}
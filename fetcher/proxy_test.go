@@ -0,0 +1,81 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFetchThroughProxiesAndPools(t *testing.T) {
+	newProxy := func(name string, hits *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(hits, 1)
+			if r.URL.Host != "target.example" {
+				t.Errorf("%s: expected absolute-form request for target.example, got %q", name, r.URL.String())
+			}
+			w.Write([]byte(name))
+		}))
+	}
+	var aHits, bHits int32
+	proxyA, proxyB := newProxy("a", &aHits), newProxy("b", &bHits)
+	defer proxyA.Close()
+	defer proxyB.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+
+	if err := SetProxySettings(ProxySettings{
+		Proxy: proxyA.URL,
+		Pools: []ProxyPoolConfig{
+			{Name: "rotate", Proxies: []string{proxyA.URL, proxyB.URL}},
+			{Name: "backup", Strategy: ProxyStrategyFailover, Proxies: []string{deadURL, proxyB.URL}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetProxySettings(ProxySettings{}) })
+
+	f := New()
+	fetch := func(r Request) (string, error) {
+		r.URL = "http://target.example/list"
+		resp, err := f.Do(context.Background(), r)
+		if err != nil {
+			return "", err
+		}
+		return resp.Body, nil
+	}
+	mustFetch := func(r Request) string {
+		t.Helper()
+		body, err := fetch(r)
+		if err != nil {
+			t.Fatalf("%+v: %v", r, err)
+		}
+		return body
+	}
+
+	if got := mustFetch(Request{}); got != "a" {
+		t.Fatalf("global default proxy not used, got %q", got)
+	}
+	if got := mustFetch(Request{Proxy: proxyB.URL}); got != "b" {
+		t.Fatalf("request proxy should override the default, got %q", got)
+	}
+	if first, second := mustFetch(Request{ProxyPool: "rotate"}), mustFetch(Request{ProxyPool: "rotate"}); first == second {
+		t.Fatalf("round robin pool should alternate, got %q twice", first)
+	}
+	if _, err := fetch(Request{ProxyPool: "backup"}); ClassifyError(err) != ErrorClassConnection {
+		t.Fatalf("the dead proxy should fail with a connection error first, got %v", err)
+	}
+	before := atomic.LoadInt32(&bHits)
+	if got := mustFetch(Request{ProxyPool: "backup"}); got != "b" || atomic.LoadInt32(&bHits) != before+1 {
+		t.Fatalf("dead proxy should stay out of rotation during cooldown, got %q", got)
+	}
+
+	if _, err := fetch(Request{ProxyPool: "missing"}); err == nil {
+		t.Fatal("unknown proxy pool must fail the request")
+	}
+	if err := SetProxySettings(ProxySettings{Proxy: "ftp://proxy.example:21"}); err == nil {
+		t.Fatal("unsupported proxy schemes must be rejected")
+	}
+}
@@ -0,0 +1,177 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxBodySize 单次读取的页面内容上限（10MB）
+const maxBodySize = 10 << 20
+
+// defaultCommandTimeout 外部命令未指定超时时的默认值
+const defaultCommandTimeout = 60 * time.Second
+
+// Source 页面来源适配器。*Fetcher 是默认的 HTTP 适配器。
+type Source interface {
+	Do(ctx context.Context, r Request) (*Response, error)
+}
+
+var (
+	_ Source = (*Fetcher)(nil)
+	_ Source = (*FileSource)(nil)
+	_ Source = (*CommandSource)(nil)
+)
+
+// FileSource 读取本地文件作为页面；路径为目录时生成文件列表页。
+type FileSource struct {
+	Path string
+}
+
+// Do 读取文件内容，Request 中只有 Charset 和 IfModifiedSince 生效。
+func (s *FileSource) Do(ctx context.Context, r Request) (*Response, error) {
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取本地来源失败: %w", err)
+	}
+	header := http.Header{}
+	if info.IsDir() {
+		listing, err := directoryListing(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		header.Set("Content-Type", "text/html; charset=utf-8")
+		return &Response{StatusCode: http.StatusOK, Header: header, Body: listing}, nil
+	}
+
+	// 目录的修改时间不反映文件内容变化，因此仅对普通文件支持条件读取
+	lastModified := info.ModTime().UTC().Format(http.TimeFormat)
+	header.Set("Last-Modified", lastModified)
+	if r.IfModifiedSince != "" && r.IfModifiedSince == lastModified {
+		return &Response{StatusCode: http.StatusNotModified, Header: header}, nil
+	}
+
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取本地来源失败: %w", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("读取本地来源失败: %w", err)
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(s.Path))
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	text, err := decodeBody(data, contentType, r.Charset)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: http.StatusOK, Header: header, Body: text}, nil
+}
+
+// directoryListing 按文件名排序生成目录列表 HTML，每个条目包含链接、大小和修改时间。
+func directoryListing(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("读取本地目录失败: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var b strings.Builder
+	b.WriteString("<html><body><ul class=\"files\">\n")
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		name := entry.Name()
+		href := url.PathEscape(name)
+		if entry.IsDir() {
+			href += "/"
+		}
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a> <span class=\"size\">%d</span> <time>%s</time></li>\n",
+			html.EscapeString(href), html.EscapeString(name), info.Size(), info.ModTime().UTC().Format(time.RFC3339))
+	}
+	b.WriteString("</ul></body></html>")
+	return b.String(), nil
+}
+
+// CommandSource 执行外部命令，将标准输出作为页面内容，适合接入本地渲染器或预处理脚本。
+// 命令通过环境变量 GENTRY_URL 获取目标地址，请求体写入标准输入。
+type CommandSource struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// Do 运行命令并返回其标准输出，命令以非零状态退出时返回错误。
+func (s *CommandSource) Do(ctx context.Context, r Request) (*Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.Command, s.Args...)
+	cmd.Env = append(os.Environ(), "GENTRY_URL="+r.URL)
+	if r.Body != "" {
+		cmd.Stdin = strings.NewReader(r.Body)
+	}
+	stdout := &limitedBuffer{limit: maxBodySize}
+	stderr := &limitedBuffer{limit: 1024}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("外部命令超时: %w", ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("外部命令执行失败: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("外部命令执行失败: %w", err)
+	}
+	text, err := decodeBody(stdout.Bytes(), "", r.Charset)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: text}, nil
+}
+
+// limitedBuffer 超出上限的输出直接丢弃，避免外部命令耗尽内存。
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.Buffer.Write(p[:remaining])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,68 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFileSourceReadsFilesAndDirectories(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(page, []byte(`<html><body><ul><li><a href="/a">公告 A</a></li></ul></body></html>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report 2.pdf"), []byte("pdf"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := (&FileSource{Path: page}).Do(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	if !strings.Contains(resp.Body, "公告 A") || resp.Header.Get("Last-Modified") == "" {
+		t.Fatalf("unexpected file response: %+v", resp)
+	}
+	cached, err := (&FileSource{Path: page}).Do(context.Background(), Request{IfModifiedSince: resp.Header.Get("Last-Modified")})
+	if err != nil || !cached.NotModified() {
+		t.Fatalf("unchanged file should answer 304, got %+v (%v)", cached, err)
+	}
+
+	listing, err := (&FileSource{Path: dir}).Do(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("list directory: %v", err)
+	}
+	if !strings.Contains(listing.Body, `<a href="page.html">page.html</a>`) || !strings.Contains(listing.Body, `<a href="report%202.pdf">report 2.pdf</a>`) {
+		t.Fatalf("directory listing should link every file, got %s", listing.Body)
+	}
+	if strings.Index(listing.Body, "page.html") > strings.Index(listing.Body, "report 2.pdf") {
+		t.Fatal("directory entries should be sorted by name")
+	}
+
+	if _, err := (&FileSource{Path: filepath.Join(dir, "missing.html")}).Do(context.Background(), Request{}); err == nil {
+		t.Fatal("missing files must fail")
+	}
+}
+
+func TestCommandSourceUsesCommandOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires /bin/sh")
+	}
+	source := &CommandSource{Command: "/bin/sh", Args: []string{"-c", `printf '<a href="%s">渲染结果</a>' "$GENTRY_URL"; cat`}}
+	resp, err := source.Do(context.Background(), Request{URL: "https://example.com/list", Body: "<p>stdin</p>"})
+	if err != nil {
+		t.Fatalf("run command: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Body != `<a href="https://example.com/list">渲染结果</a><p>stdin</p>` {
+		t.Fatalf("unexpected command output: %+v", resp)
+	}
+
+	failing := &CommandSource{Command: "/bin/sh", Args: []string{"-c", "echo boom >&2; exit 3"}}
+	if _, err := failing.Do(context.Background(), Request{}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected command failure with stderr, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// TLSOptions 站点级 TLS 设置，为空时使用系统根证书和默认配置。
+type TLSOptions struct {
+	// CAPEM 额外信任的 CA 证书（PEM），在系统根证书基础上追加
+	CAPEM string `json:"ca_pem,omitempty"`
+	// ClientCertPEM/ClientKeyPEM 双向 TLS 客户端证书和私钥（PEM）
+	ClientCertPEM string `json:"client_cert_pem,omitempty"`
+	ClientKeyPEM  string `json:"client_key_pem,omitempty"`
+	// MinVersion 最低 TLS 版本: 1.0、1.1、1.2、1.3，默认 1.2
+	MinVersion string `json:"min_version,omitempty"`
+	// InsecureSkipVerify 跳过证书校验，仅用于无法配置 CA 的测试环境
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Config 构造 tls.Config，同时校验证书和私钥。
+func (o *TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: o.InsecureSkipVerify}
+	if o.MinVersion != "" {
+		version, ok := tlsVersions[o.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("TLS 最低版本仅支持 1.0、1.1、1.2、1.3: %s", o.MinVersion)
+		}
+		config.MinVersion = version
+	}
+	if o.CAPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(o.CAPEM)) {
+			return nil, fmt.Errorf("CA 证书无效：未解析到 PEM 格式的证书")
+		}
+		config.RootCAs = pool
+	}
+	if (o.ClientCertPEM == "") != (o.ClientKeyPEM == "") {
+		return nil, fmt.Errorf("客户端证书和私钥必须同时配置")
+	}
+	if o.ClientCertPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(o.ClientCertPEM), []byte(o.ClientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("客户端证书或私钥无效: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// tlsClients 按 TLS 设置缓存 http.Client，相同设置的站点共享连接池。
+var tlsClients sync.Map // 设置摘要 -> *http.Client
+
+// clientFor 返回请求使用的 http.Client：未配置 TLS 时使用 Fetcher 自身的客户端，
+// 否则使用带对应 TLS 设置、同样受地址拦截和代理设置约束的客户端。
+func (f *Fetcher) clientFor(options *TLSOptions) (*http.Client, error) {
+	if options == nil {
+		return f.config.client, nil
+	}
+	data, _ := json.Marshal(options)
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:]) + "/" + f.config.client.Timeout.String()
+	if client, ok := tlsClients.Load(key); ok {
+		return client.(*http.Client), nil
+	}
+	tlsConfig, err := options.Config()
+	if err != nil {
+		return nil, err
+	}
+	if options.InsecureSkipVerify {
+		log.Printf("[抓取] 警告: 站点配置跳过了 TLS 证书校验，连接可能被中间人窃听或篡改")
+	}
+	transport := NewTransport()
+	transport.TLSClientConfig = tlsConfig
+	client := &http.Client{Timeout: f.config.client.Timeout, Transport: transport}
+	actual, _ := tlsClients.LoadOrStore(key, client)
+	return actual.(*http.Client), nil
+}
@@ -0,0 +1,76 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchWithTLSOptions(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "gentry-client"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	clientCertPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	clientKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	f := New()
+	fetch := func(options TLSOptions) error {
+		_, err := f.Do(context.Background(), Request{URL: server.URL, TLS: &options})
+		return err
+	}
+
+	if err := fetch(TLSOptions{ClientCertPEM: clientCertPEM, ClientKeyPEM: clientKeyPEM}); err == nil {
+		t.Fatal("private CA must not be trusted by default")
+	}
+	if err := fetch(TLSOptions{CAPEM: caPEM}); err == nil {
+		t.Fatal("server requiring mTLS should reject requests without a client certificate")
+	}
+	if err := fetch(TLSOptions{CAPEM: caPEM, ClientCertPEM: clientCertPEM, ClientKeyPEM: clientKeyPEM, MinVersion: "1.2"}); err != nil {
+		t.Fatalf("custom CA with client certificate should succeed: %v", err)
+	}
+	if err := fetch(TLSOptions{InsecureSkipVerify: true, ClientCertPEM: clientCertPEM, ClientKeyPEM: clientKeyPEM}); err != nil {
+		t.Fatalf("skip-verify should accept the private certificate: %v", err)
+	}
+
+	if _, err := (&TLSOptions{CAPEM: "not a certificate"}).Config(); err == nil {
+		t.Fatal("invalid CA certificates must be rejected")
+	}
+	if _, err := (&TLSOptions{ClientCertPEM: clientCertPEM}).Config(); err == nil {
+		t.Fatal("a client certificate without its key must be rejected")
+	}
+	if _, err := (&TLSOptions{MinVersion: "1.4"}).Config(); err == nil {
+		t.Fatal("unknown TLS versions must be rejected")
+	}
+}
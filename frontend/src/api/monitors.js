@@ -215,6 +215,21 @@ export function manualCheck(name) {
   return client.post(`/monitors/${encodeURIComponent(name)}/check`).then(r => r.data)
 }
 
+// 获取选择器修复建议
+export function fetchRepairs(name) {
+  return client.get(`/monitors/${encodeURIComponent(name)}/repairs`).then(r => r.data)
+}
+
+// 接受选择器修复建议
+export function acceptRepair(name, id) {
+  return client.post(`/monitors/${encodeURIComponent(name)}/repairs/${id}/accept`).then(r => r.data)
+}
+
+// 忽略选择器修复建议
+export function dismissRepair(name, id) {
+  return client.post(`/monitors/${encodeURIComponent(name)}/repairs/${id}/dismiss`).then(r => r.data)
+}
+
 // 验证监控配置
 export function validateMonitorConfig(config) {
   return client.post('/monitors/validate', config).then(r => r.data)
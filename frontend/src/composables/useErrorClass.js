@@ -0,0 +1,15 @@
+const ERROR_CLASS_LABELS = {
+  dns: '域名解析失败',
+  timeout: '超时',
+  connection: '连接中断',
+  http_4xx: '页面错误',
+  http_5xx: '站点故障',
+  blocked: '被站点拦截',
+  policy: '策略拒绝',
+  canceled: '已取消',
+  other: '错误',
+}
+
+export function errorClassLabel(errorClass) {
+  return ERROR_CLASS_LABELS[errorClass] || '错误'
+}
@@ -32,6 +32,17 @@ export function createEmptyForm() {
         minPercent: '',
         targetPrice: '',
       },
+      watch: {
+        fields: [],
+        ignoreWhitespace: false,
+        ignoreCase: false,
+        ignoreNumberFormat: false,
+      },
+      freshness: {
+        field: '',
+        maxAgeDays: '',
+        timezone: '',
+      },
     },
 
     notification: {
@@ -61,9 +72,12 @@ export function toMonitorRequest(form) {
       type: f.type || 'text',
       attr: f.attr || '',
       transform: f.transform || '',
+      scope: f.scope || 'list',
     })),
   }
 
+  const freshness = freshnessConfig(form.rule.freshness)
+
   if (form.monitorType === 'field_transition') {
     const identity = form.rule.identity.mode === 'source_url'
       ? { source: 'source_url' }
@@ -98,6 +112,7 @@ export function toMonitorRequest(form) {
       identity: identity,
       conditions: [condition],
       on_first_baseline: 'silent',
+      ...freshness,
     }
 
     if (form.rule.target.field) {
@@ -105,11 +120,57 @@ export function toMonitorRequest(form) {
         [form.rule.target.field]: 'money',
       }
     }
+  } else if (form.monitorType === 'field_changed') {
+    const identity = form.rule.identity.mode === 'source_url'
+      ? { source: 'source_url' }
+      : { field: form.rule.identity.field }
+
+    const watch = { fields: [...form.rule.watch.fields] }
+    if (form.rule.watch.ignoreWhitespace) watch.ignore_whitespace = true
+    if (form.rule.watch.ignoreCase) watch.ignore_case = true
+    if (form.rule.watch.ignoreNumberFormat) watch.ignore_number_format = true
+
+    payload.strategy_config = {
+      type: 'field_changed',
+      identity: identity,
+      watch,
+      on_first_baseline: 'silent',
+      ...freshness,
+    }
+  } else if (freshness.published_field) {
+    payload.strategy_config = {
+      type: 'presence',
+      identity: { source: 'source_url' },
+      on_first_baseline: 'silent',
+      ...freshness,
+    }
+  }
+
+  if (freshness.published_field) {
+    payload.field_data_types = {
+      ...(payload.field_data_types || {}),
+      [freshness.published_field]: 'datetime',
+    }
   }
 
   return payload
 }
 
+// freshnessConfig 生成发布时间相关的策略配置：发布时间字段、时区和最大条目天数
+function freshnessConfig(freshness) {
+  const config = {}
+  const field = (freshness?.field || '').trim()
+  if (!field) return config
+  config.published_field = field
+  if (freshness.timezone && freshness.timezone.trim()) {
+    config.timezone = freshness.timezone.trim()
+  }
+  if (Number(freshness.maxAgeDays) > 0) {
+    config.max_age_days = Math.floor(Number(freshness.maxAgeDays))
+  }
+  return config
+}
+
 export function fromMonitorResponse(data) {
   const form = createEmptyForm()
 
@@ -132,6 +193,7 @@ export function fromMonitorResponse(data) {
       type: f.type || 'text',
       attr: f.attr || '',
       transform: f.transform || '',
+      scope: f.scope || 'list',
     }))
   }
 
@@ -150,6 +212,17 @@ export function fromMonitorResponse(data) {
         form.rule.identity.field = sc.identity.field
       }
     }
+    if (sc && sc.published_field) {
+      form.rule.freshness.field = sc.published_field
+      form.rule.freshness.maxAgeDays = sc.max_age_days || ''
+      form.rule.freshness.timezone = sc.timezone || ''
+    }
+    if (sc && sc.watch) {
+      form.rule.watch.fields = Array.isArray(sc.watch.fields) ? [...sc.watch.fields] : []
+      form.rule.watch.ignoreWhitespace = Boolean(sc.watch.ignore_whitespace)
+      form.rule.watch.ignoreCase = Boolean(sc.watch.ignore_case)
+      form.rule.watch.ignoreNumberFormat = Boolean(sc.watch.ignore_number_format)
+    }
     if (sc && sc.conditions && sc.conditions.length > 0) {
       const cond = sc.conditions[0]
       form.rule.target.field = cond.field || 'price'
@@ -165,7 +238,7 @@ export function fromMonitorResponse(data) {
 
   const fieldDataTypes = parseJSONValue(data.field_data_types)
   if (fieldDataTypes && typeof fieldDataTypes === 'object') {
-    const keys = Object.keys(fieldDataTypes)
+    const keys = Object.keys(fieldDataTypes).filter(key => fieldDataTypes[key] !== 'datetime')
     if (keys.length > 0 && !form.rule.target.field) {
       form.rule.target.field = keys[0]
     }
@@ -240,6 +313,15 @@ export function validateForm(form) {
     fieldNames.add(name)
   }
 
+  const publishedField = (form.rule.freshness?.field || '').trim()
+  if (publishedField) {
+    if (!fieldNames.has(publishedField)) return '发布时间字段必须存在于提取字段中'
+    if (form.monitorType === 'field_transition' && publishedField === form.rule.target.field.trim()) {
+      return '发布时间字段不能与价格字段相同'
+    }
+  }
+  if (form.rule.freshness?.maxAgeDays && !publishedField) return '忽略旧条目需要先选择发布时间字段'
+
   if (form.monitorType === 'field_transition') {
     if (!form.rule.target.field.trim()) return '监控字段名称不能为空'
     if (!fieldNames.has(form.rule.target.field.trim())) return '监控字段必须存在于提取字段中'
@@ -277,6 +359,22 @@ export function validateForm(form) {
     }
   }
 
+  if (form.monitorType === 'field_changed') {
+    const watched = form.rule.watch.fields
+    if (!watched.length) return '至少选择一个被监控字段'
+    const missing = watched.find(name => !fieldNames.has(name))
+    if (missing) return `被监控字段不存在: ${missing}`
+    if (form.extraction.itemSelector.trim() && form.rule.identity.mode !== 'field') {
+      return '列表页必须使用稳定且唯一的字段作为条目身份'
+    }
+    if (form.rule.identity.mode === 'field') {
+      const identityField = form.rule.identity.field.trim()
+      if (!identityField) return '必须指定条目身份字段'
+      if (!fieldNames.has(identityField)) return '条目身份字段必须存在于提取字段中'
+      if (watched.includes(identityField)) return '条目身份字段不能同时作为被监控字段'
+    }
+  }
+
   if (form.notification.filter === 'keyword' && !form.notification.keywords.trim()) {
     return '选择关键词过滤时必须填写推送关键词'
   }
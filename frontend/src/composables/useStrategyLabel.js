@@ -0,0 +1,14 @@
+const STRATEGY_LABELS = {
+  presence: '新增检测',
+  field_transition: '价格监控',
+  field_changed: '字段变化',
+}
+
+export function strategyLabel(strategyType) {
+  return STRATEGY_LABELS[strategyType] || STRATEGY_LABELS.presence
+}
+
+// usesEventEngine 与后端 monitor.UsesEventEngine 一致：这些策略使用快照基线和事件历史
+export function usesEventEngine(strategyType) {
+  return strategyType === 'field_transition' || strategyType === 'field_changed'
+}
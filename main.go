@@ -3,16 +3,19 @@ package main
 import (
 	"context"
 	"embed"
+	"errors"
 	"io/fs"
 	"log"
 	"os"
 	"os/exec"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/cn-maul/Gentry/database"
+	"github.com/cn-maul/Gentry/fetcher"
 	"github.com/cn-maul/Gentry/monitor"
 	"github.com/cn-maul/Gentry/notify"
 	"github.com/cn-maul/Gentry/web"
@@ -41,6 +44,28 @@ func main() {
 	}
 
 	monitor.InitScanRules(os.Getenv("SCAN_RULES_FILE"))
+	monitor.SetLocalSourcesEnabled(os.Getenv("ALLOW_LOCAL_SOURCES") == "true")
+	allowedNetworks, err := fetcher.ParseAllowedNetworks(os.Getenv("ALLOW_PRIVATE_NETWORKS"))
+	if err != nil {
+		log.Fatalf("ALLOW_PRIVATE_NETWORKS 配置无效: %v", err)
+	}
+	fetcher.SetAllowedNetworks(allowedNetworks)
+	politeness, err := loadPoliteness()
+	if err != nil {
+		log.Fatalf("主机访问约束配置无效: %v", err)
+	}
+	fetcher.SetPoliteness(politeness)
+	log.Printf("[抓取] 同主机请求间隔 %v，并发上限 %d，遵守 robots.txt: %v",
+		politeness.MinInterval, politeness.MaxConcurrent, politeness.RespectRobots)
+	cacheTTL, err := loadCacheTTL()
+	if err != nil {
+		log.Fatalf("响应缓存配置无效: %v", err)
+	}
+	fetcher.SetCacheTTL(cacheTTL)
+
+	if err := web.LoadProxySettings(); err != nil {
+		log.Printf("[抓取] 代理设置无效，已忽略: %v", err)
+	}
 
 	// 2. 从数据库加载并启动所有活跃的监控器
 	monitor.StartAllFromDB()
@@ -111,3 +136,37 @@ func runCmd(name string, args ...string) {
 	cmd.Stderr = nil
 	_ = cmd.Run()
 }
+
+// loadPoliteness 从环境变量读取同主机访问约束，未设置的项使用默认值。
+func loadPoliteness() (fetcher.Politeness, error) {
+	politeness := fetcher.DefaultPoliteness
+	if raw := os.Getenv("HOST_MIN_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			return politeness, errors.New("HOST_MIN_INTERVAL 必须是非负时长，如 1s、500ms")
+		}
+		politeness.MinInterval = interval
+	}
+	if raw := os.Getenv("HOST_MAX_CONCURRENCY"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return politeness, errors.New("HOST_MAX_CONCURRENCY 必须是非负整数")
+		}
+		politeness.MaxConcurrent = limit
+	}
+	politeness.RespectRobots = os.Getenv("RESPECT_ROBOTS_TXT") == "true"
+	return politeness, nil
+}
+
+// loadCacheTTL 从环境变量读取响应缓存有效期，0 表示关闭缓存。
+func loadCacheTTL() (time.Duration, error) {
+	raw := os.Getenv("FETCH_CACHE_TTL")
+	if raw == "" {
+		return fetcher.DefaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl < 0 {
+		return 0, errors.New("FETCH_CACHE_TTL 必须是非负时长，如 30s，0 表示关闭")
+	}
+	return ttl, nil
+}
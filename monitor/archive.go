@@ -0,0 +1,139 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cn-maul/Gentry/database"
+	"github.com/cn-maul/Gentry/fetcher"
+)
+
+const (
+	defaultArchiveMaxCount = 20
+	maxArchiveMaxCount     = 500
+	maxArchiveMaxDays      = 365
+	maxReplayItems         = 50
+)
+
+// ArchiveConfig 页面存档配置，配置后每次检查保存抓取到的列表页。
+type ArchiveConfig struct {
+	// MaxCount 每个站点最多保留的存档数量（按页计），默认 20，上限 500
+	MaxCount int `json:"max_count,omitempty"`
+	// MaxDays 存档保留天数，0 表示只按数量清理
+	MaxDays int `json:"max_days,omitempty"`
+}
+
+func (c *ArchiveConfig) normalize() error {
+	switch {
+	case c.MaxCount == 0:
+		c.MaxCount = defaultArchiveMaxCount
+	case c.MaxCount < 1 || c.MaxCount > maxArchiveMaxCount:
+		return fmt.Errorf("存档数量必须在 1-%d 之间", maxArchiveMaxCount)
+	}
+	if c.MaxDays < 0 || c.MaxDays > maxArchiveMaxDays {
+		return fmt.Errorf("存档保留天数必须在 0-%d 之间", maxArchiveMaxDays)
+	}
+	return nil
+}
+
+// pageRecorder 在提取前接收每一页的原始响应。
+type pageRecorder func(page int, pageURL string, resp *fetcher.Response)
+
+// newPageArchiver 返回按站点存档配置保存页面的记录器，未开启存档时返回 nil。
+// 存档失败只记录日志，不影响本次检查。
+func newPageArchiver(site *database.Site, config *FetchConfig) pageRecorder {
+	if config.Archive == nil {
+		return nil
+	}
+	maxCount := config.Archive.MaxCount
+	maxAge := time.Duration(config.Archive.MaxDays) * 24 * time.Hour
+	return func(page int, pageURL string, resp *fetcher.Response) {
+		archive := &database.PageArchive{
+			SiteID:            site.ID,
+			DefinitionVersion: site.ConfigVersion,
+			URL:               pageURL,
+			Page:              page,
+			StatusCode:        resp.StatusCode,
+			ContentType:       resp.Header.Get("Content-Type"),
+		}
+		if err := database.SavePageArchive(archive, []byte(resp.Body), maxCount, maxAge); err != nil {
+			log.Printf("[%s] 保存页面存档失败: %v", site.Name, err)
+		}
+	}
+}
+
+// ReplayResult 存档回放结果，结构与一次检查的结果一致。
+type ReplayResult struct {
+	CheckOutcome
+	ArchiveID      uint            `json:"archive_id"`
+	ExtractedItems int             `json:"extracted_items"`
+	Items          []ExtractResult `json:"items"`
+}
+
+// ReplayArchive 用给定监控定义重新提取存档页面，并与当前基线比较检测结果。
+// 回放只读：不访问网络（包括详情页）、不写入快照、事件或更新记录。
+func ReplayArchive(site *database.Site, archive *database.PageArchive) (*ReplayResult, error) {
+	engine, err := NewEngine(site)
+	if err != nil {
+		return nil, err
+	}
+	body, err := archive.Content()
+	if err != nil {
+		return nil, err
+	}
+	page := &fetcher.Response{StatusCode: archive.StatusCode, Header: http.Header{}, Body: string(body)}
+	if archive.ContentType != "" {
+		page.Header.Set("Content-Type", archive.ContentType)
+	}
+	if err := engine.fetchConfig.detectChallenge(archive.URL, page); err != nil {
+		return nil, err
+	}
+	results, err := engine.extractor.Extract(page.Body)
+	if err != nil {
+		return nil, fmt.Errorf("extraction failed: %w", err)
+	}
+	if err := ResolveExtractedURLs(archive.URL, results); err != nil {
+		return nil, fmt.Errorf("resolve URLs failed: %w", err)
+	}
+
+	site = engine.site
+	result := &ReplayResult{
+		// 回放不抓取详情页，只统计列表字段
+		CheckOutcome:   CheckOutcome{StrategyType: site.StrategyType, FieldStats: siteFieldHitStats(site, results, nil, engine.parseFieldDataTypes())},
+		ArchiveID:      archive.ID,
+		ExtractedItems: len(results),
+		Items:          results,
+	}
+	if len(results) > maxReplayItems {
+		result.Items = results[:maxReplayItems]
+	}
+
+	if UsesEventEngine(site.StrategyType) {
+		observations, err := engine.mergePages([][]ExtractResult{results})
+		if err != nil {
+			return nil, err
+		}
+		snapshots, err := LoadSnapshots(site.ID, site.ConfigVersion)
+		if err != nil {
+			return nil, fmt.Errorf("load snapshots failed: %w", err)
+		}
+		evaluation := engine.detector.Evaluate(snapshots, observations)
+		result.IsFirstBaseline = len(snapshots) == 0
+		if !result.IsFirstBaseline || engine.rule.OnFirstBaseline != "silent" {
+			result.Events = evaluation.Events
+		}
+		return result, nil
+	}
+
+	last, err := loadLastResults(site)
+	if err != nil {
+		return nil, err
+	}
+	result.IsFirstBaseline = len(last) == 0
+	if !result.IsFirstBaseline {
+		result.Updates = engine.rule.dropStale(compareResults(last, results), time.Now())
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cn-maul/Gentry/fetcher"
+)
+
+const maxBlockedPatterns = 20
+
+// challengeSignature 常见反爬验证页和登录墙的特征。
+type challengeSignature struct {
+	name string
+	// header 非空时检查响应头的值是否包含 value，否则检查正文
+	header string
+	value  string
+	// markers 非空时正文还须包含其中之一，用于区分通用标记与站点自身的同名页面
+	markers []string
+}
+
+// loginPathRegex 登录墙通常把请求重定向到登录页。
+var loginPathRegex = regexp.MustCompile(`(?i)/(login|signin|sign-in|sign_in|passport|sso|auth)(/|\.|$)`)
+
+// builtinChallengeSignatures 只收录验证页特有的标记，避免把普通页面中的“登录”“验证”误判为拦截；
+// 页面中嵌入的验证码组件（如评论框）不足以说明整页被拦截，需要时用 blocked_patterns 自行配置。
+var builtinChallengeSignatures = []challengeSignature{
+	{name: "Cloudflare 验证", header: "Cf-Mitigated", value: "challenge"},
+	{name: "Cloudflare 验证", value: "cf-browser-verification"},
+	{name: "Cloudflare 验证", value: "<title>just a moment...</title>"},
+	{name: "Cloudflare 拦截", value: "attention required! | cloudflare"},
+	{name: "DataDome 验证", value: "captcha-delivery.com"},
+	{name: "PerimeterX 验证", value: "px-captcha"},
+	// 站点自己的 403 页面也常用这个标题，需同时带有 Akamai 的参考编号或错误页地址（可能以 HTML 实体编码）
+	{name: "Akamai 拦截", value: "<title>access denied</title>", markers: []string{
+		"reference #", "reference&#32;&#35;", "errors.edgesuite.net", "errors&#46;edgesuite&#46;net",
+	}},
+	{name: "阿里云 WAF 验证", value: "_waf_bd8ce2ce37"},
+}
+
+// compileBlockedPatterns 校验用户配置的拦截页正则，返回编译结果。
+func compileBlockedPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	if len(patterns) > maxBlockedPatterns {
+		return nil, fmt.Errorf("拦截页特征最多配置 %d 条", maxBlockedPatterns)
+	}
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("拦截页特征 %q 不是有效的正则表达式: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// detectChallenge 判断响应是否为反爬验证页或登录墙，命中时返回包装 fetcher.ErrChallengePage 的错误。
+// 拦截页通常返回 200，若不识别会被当作选择器失效，甚至让新增监控误判条目全部消失。
+func (c *FetchConfig) detectChallenge(requestURL string, resp *fetcher.Response) error {
+	if redirectedToLogin(requestURL, resp) {
+		final, _ := url.Parse(resp.URL)
+		return fmt.Errorf("%w: 被重定向到登录页 %s", fetcher.ErrChallengePage, final.Redacted())
+	}
+	for _, re := range c.blockedPatterns {
+		if re.MatchString(resp.Body) {
+			return fmt.Errorf("%w: 命中拦截页特征 %s", fetcher.ErrChallengePage, re.String())
+		}
+	}
+	var lowerBody string
+	for _, signature := range builtinChallengeSignatures {
+		if signature.header != "" {
+			if strings.Contains(strings.ToLower(resp.Header.Get(signature.header)), signature.value) {
+				return fmt.Errorf("%w: %s", fetcher.ErrChallengePage, signature.name)
+			}
+			continue
+		}
+		if lowerBody == "" {
+			lowerBody = strings.ToLower(resp.Body)
+		}
+		if strings.Contains(lowerBody, signature.value) && containsAny(lowerBody, signature.markers) {
+			return fmt.Errorf("%w: %s", fetcher.ErrChallengePage, signature.name)
+		}
+	}
+	return nil
+}
+
+// containsAny 报告 s 是否包含 markers 之一，markers 为空时视为满足。
+func containsAny(s string, markers []string) bool {
+	if len(markers) == 0 {
+		return true
+	}
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redirectedToLogin 报告请求是否被重定向到了登录路径（请求本身就是登录页时除外）。
+func redirectedToLogin(requestURL string, resp *fetcher.Response) bool {
+	if resp.URL == "" || resp.URL == requestURL {
+		return false
+	}
+	final, err := url.Parse(resp.URL)
+	if err != nil || !loginPathRegex.MatchString(final.Path) {
+		return false
+	}
+	requested, err := url.Parse(requestURL)
+	return err != nil || !loginPathRegex.MatchString(requested.Path)
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cn-maul/Gentry/database"
+	"github.com/cn-maul/Gentry/fetcher"
+)
+
+func TestChallengePagesMarkCheckBlocked(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var mode atomic.Value
+	mode.Store("list")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/account/login":
+			w.Write([]byte(`<form>账号</form>`))
+		case mode.Load() == "cloudflare":
+			w.Write([]byte(`<html><head><title>Just a moment...</title></head><body><script src="https://challenges.cloudflare.com/x.js"></script></body></html>`))
+		case mode.Load() == "custom":
+			w.Write([]byte(`<html><body><div class="wall">请先登录后查看</div></body></html>`))
+		case mode.Load() == "turnstile":
+			// 普通页面在评论框中嵌入 Turnstile 组件，不是验证页
+			w.Write([]byte(`<ul><li><a href="/a">公告 A</a></li><li><a href="/b">公告 B</a></li></ul><form><script src="https://challenges.cloudflare.com/turnstile/v0/api.js"></script></form>`))
+		case mode.Load() == "redirect":
+			http.Redirect(w, r, "/account/login?next=/list", http.StatusFound)
+		default:
+			w.Write([]byte(`<ul><li><a href="/a">公告 A</a></li><li><a href="/b">公告 B</a></li></ul>`))
+		}
+	}))
+	defer server.Close()
+
+	site := &database.Site{
+		Name: "challenge-site", URL: server.URL + "/list", Container: "ul", Item: "li",
+		FetchConfig:   `{"retry":{"attempts":1},"blocked_patterns":["请先登录"]}`,
+		ConfigVersion: 1,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+		},
+	}
+	if err := database.CreateSiteWithFields(site); err != nil {
+		t.Fatal(err)
+	}
+	m := newMonitor(site)
+	if _, err := m.CheckNow(context.Background()); err != nil {
+		t.Fatalf("baseline check: %v", err)
+	}
+	var baseline int64
+	database.GetDB().Model(&database.UpdateRecord{}).Where("site_id = ?", site.ID).Count(&baseline)
+
+	for _, current := range []string{"cloudflare", "custom", "redirect"} {
+		mode.Store(current)
+		_, err := m.CheckNow(context.Background())
+		if !errors.Is(err, fetcher.ErrChallengePage) {
+			t.Fatalf("%s: expected challenge error, got %v", current, err)
+		}
+		if status := m.GetStatus(); !status.Blocked || status.LastErrorClass != fetcher.ErrorClassBlocked {
+			t.Fatalf("%s: status should be blocked, got %+v", current, status)
+		}
+	}
+	var records int64
+	database.GetDB().Model(&database.UpdateRecord{}).Where("site_id = ?", site.ID).Count(&records)
+	if records != baseline {
+		t.Fatalf("blocked checks must not touch stored results: %d -> %d", baseline, records)
+	}
+
+	mode.Store("list")
+	if _, err := m.CheckNow(context.Background()); err != nil {
+		t.Fatalf("recovered check: %v", err)
+	}
+	if status := m.GetStatus(); status.Blocked || status.LastErrorClass != "" {
+		t.Fatalf("successful check should clear blocked state, got %+v", status)
+	}
+	mode.Store("turnstile")
+	if _, err := m.CheckNow(context.Background()); err != nil {
+		t.Fatalf("a page embedding the Turnstile widget should not be blocked: %v", err)
+	}
+
+	if _, err := ParseFetchConfig(`{"blocked_patterns":["("]}`); err == nil || !strings.Contains(err.Error(), "正则") {
+		t.Fatalf("invalid blocked pattern must be rejected, got %v", err)
+	}
+}
+
+func TestAkamaiSignatureRequiresAkamaiMarker(t *testing.T) {
+	config := &FetchConfig{}
+	own403 := `<html><head><title>Access Denied</title></head><body>您没有权限访问该页面</body></html>`
+	if err := config.detectChallenge("https://example.com/", &fetcher.Response{Body: own403}); err != nil {
+		t.Fatalf("a site's own access denied page must not be treated as Akamai, got %v", err)
+	}
+	akamai := `<HTML><HEAD><TITLE>Access Denied</TITLE></HEAD><BODY><H1>Access Denied</H1>
+Reference&#32;&#35;18&#46;2d3e4f5a&#46;1700000000&#46;abcdef
+<P>https&#58;&#47;&#47;errors&#46;edgesuite&#46;net&#47;18&#46;2d3e4f5a</P></BODY></HTML>`
+	if err := config.detectChallenge("https://example.com/", &fetcher.Response{Body: akamai}); !errors.Is(err, fetcher.ErrChallengePage) {
+		t.Fatalf("expected the Akamai denial page to be detected, got %v", err)
+	}
+}
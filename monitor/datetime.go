@@ -0,0 +1,243 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// relativeTimeRegex 匹配“3小时前”“5 分钟前”“2 days ago”等相对时间
+	relativeTimeRegex = regexp.MustCompile(`(?i)^(\d+)\s*(秒|秒钟|分|分钟|小时|个小时|天|日|周|星期|个星期|月|个月|年|seconds?|secs?|minutes?|mins?|hours?|hrs?|days?|weeks?|months?|years?)\s*(?:前|以前|之前|ago)$`)
+	// dayWordRegex 匹配“今天 14:30”“昨天”“前天 08:00”
+	dayWordRegex = regexp.MustCompile(`(?i)^(今天|今日|昨天|昨日|前天|today|yesterday)\s*(?:(\d{1,2}):(\d{2})(?::(\d{2}))?)?$`)
+	// calendarDateRegex 匹配“2026年10月17日”“2026-10-17 14:30”“10-17”“10月17日”等日期，年份可省略；
+	// 匹配结果还需经 matchCalendarDate 检查边界，排除版本号、小数等数字片段
+	calendarDateRegex = regexp.MustCompile(`(?:(\d{4})\s*[年/.-]\s*)?(\d{1,2})\s*([月/.-])\s*(\d{1,2})\s*[日号]?(?:\s*T?\s*(\d{1,2})\s*[:：时]\s*(\d{2})(?:\s*[:：分]\s*(\d{2}))?)?`)
+	unixTimeRegex     = regexp.MustCompile(`^\d{10}(?:\d{3})?$`)
+)
+
+// normalizeDateTime 把日期时间解析为 loc 时区下的 RFC 3339 时间；
+// 相对时间按 now 推算，只精确到原文的单位（“3小时前”取整到小时）。推算结果会随检查时间推移而变化，
+// 相对时间字段只适合判断条目新旧，不能作为条目身份或被监控的字段。
+func normalizeDateTime(value string, loc *time.Location, now time.Time) TypedValue {
+	parsed, ok := parseDateTime(strings.TrimSpace(value), loc, now.In(loc))
+	if !ok {
+		return TypedValue{DataType: "datetime", Valid: false}
+	}
+	return TypedValue{Value: parsed.Format(time.RFC3339), DataType: "datetime", Time: parsed, Valid: true}
+}
+
+func parseDateTime(value string, loc *time.Location, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339, time.RFC1123Z, time.RFC1123} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.In(loc), true
+		}
+	}
+	if unixTimeRegex.MatchString(value) {
+		seconds, _ := strconv.ParseInt(value, 10, 64)
+		if len(value) == 13 {
+			return time.UnixMilli(seconds).In(loc), true
+		}
+		return time.Unix(seconds, 0).In(loc), true
+	}
+	lower := strings.ToLower(value)
+	if lower == "刚刚" || lower == "just now" {
+		return now.Truncate(time.Minute), true
+	}
+	if m := relativeTimeRegex.FindStringSubmatch(lower); m != nil {
+		return relativeTime(now, m[1], m[2])
+	}
+	if m := dayWordRegex.FindStringSubmatch(lower); m != nil {
+		day := startOfDay(now)
+		switch m[1] {
+		case "昨天", "昨日", "yesterday":
+			day = day.AddDate(0, 0, -1)
+		case "前天":
+			day = day.AddDate(0, 0, -2)
+		}
+		return withClock(day, m[2], m[3], m[4])
+	}
+	if m := matchCalendarDate(value); m != nil {
+		month, _ := strconv.Atoi(m[2])
+		dayOfMonth, _ := strconv.Atoi(m[4])
+		year := now.Year()
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		date := time.Date(year, time.Month(month), dayOfMonth, 0, 0, 0, 0, loc)
+		if date.Month() != time.Month(month) || date.Day() != dayOfMonth {
+			return time.Time{}, false
+		}
+		// 省略年份的日期（如 12-30）晚于明天时视为去年
+		if m[1] == "" && date.After(startOfDay(now).AddDate(0, 0, 1)) {
+			date = date.AddDate(-1, 0, 0)
+		}
+		return withClock(date, m[5], m[6], m[7])
+	}
+	return time.Time{}, false
+}
+
+// matchCalendarDate 返回 value 中第一个可信的日期匹配。日期前后不能紧邻字母、数字或分隔符，
+// 以免把“v3.2”“1.2.3”当作日期；省略年份且用 /、.、- 分隔的日期容易与小数、比分、页码混淆，
+// 要求月和日都是两位数且构成整个值（如“10-17”），“3.2 万”“1-2”不视为日期。
+func matchCalendarDate(value string) []string {
+	for _, loc := range calendarDateRegex.FindAllStringSubmatchIndex(value, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 && isDateNeighbor(value[start-1]) || end < len(value) && isDateNeighbor(value[end]) {
+			continue
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = value[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		if m[1] == "" && m[3] != "月" && (start > 0 || end < len(value) || len(m[2]) != 2 || len(m[4]) != 2) {
+			continue
+		}
+		return m
+	}
+	return nil
+}
+
+func isDateNeighbor(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '.' || c == '-' || c == '/'
+}
+
+func relativeTime(now time.Time, amount, unit string) (time.Time, bool) {
+	n, err := strconv.Atoi(amount)
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch {
+	case strings.HasPrefix(unit, "秒") || strings.HasPrefix(unit, "sec"):
+		return now.Add(-time.Duration(n) * time.Second).Truncate(time.Minute), true
+	case strings.HasPrefix(unit, "分") || strings.HasPrefix(unit, "min"):
+		return now.Add(-time.Duration(n) * time.Minute).Truncate(time.Minute), true
+	case strings.Contains(unit, "小时") || strings.HasPrefix(unit, "h"):
+		return now.Add(-time.Duration(n) * time.Hour).Truncate(time.Hour), true
+	case unit == "天" || unit == "日" || strings.HasPrefix(unit, "day"):
+		return startOfDay(now).AddDate(0, 0, -n), true
+	case strings.Contains(unit, "周") || strings.Contains(unit, "星期") || strings.HasPrefix(unit, "week"):
+		return startOfDay(now).AddDate(0, 0, -7*n), true
+	case strings.Contains(unit, "月") || strings.HasPrefix(unit, "month"):
+		return startOfDay(now).AddDate(0, -n, 0), true
+	case unit == "年" || strings.HasPrefix(unit, "year"):
+		return startOfDay(now).AddDate(-n, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// withClock 为日期补上时分秒，未给出时间时保持零点。
+func withClock(day time.Time, hour, minute, second string) (time.Time, bool) {
+	if hour == "" {
+		return day, true
+	}
+	h, _ := strconv.Atoi(hour)
+	m, _ := strconv.Atoi(minute)
+	s, _ := strconv.Atoi(second)
+	if h > 23 || m > 59 || s > 59 {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, s, 0, day.Location()), true
+}
+
+// loadTimezone 解析时区名称，为空时使用服务器本地时区（TZ 环境变量）。
+func loadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("时区 %s 无效: %w", name, err)
+	}
+	return loc, nil
+}
+
+// location 返回规则配置的时区，配置已在保存时校验，解析失败时回退到本地时区。
+func (r *DetectionRule) location() *time.Location {
+	loc, err := loadTimezone(r.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// resolvePublishedField 确定作为发布时间的字段：未配置时使用唯一的 datetime 字段。
+func resolvePublishedField(rule *DetectionRule, dataTypes map[string]string) error {
+	if _, err := loadTimezone(rule.Timezone); err != nil {
+		return err
+	}
+	if rule.MaxAgeDays < 0 {
+		return fmt.Errorf("max_age_days 不能为负数")
+	}
+	if rule.PublishedField != "" {
+		if dataTypes[rule.PublishedField] != "datetime" {
+			return fmt.Errorf("发布时间字段 %s 的数据类型必须为 datetime", rule.PublishedField)
+		}
+		return nil
+	}
+	var candidates []string
+	for field, dataType := range dataTypes {
+		if dataType == "datetime" {
+			candidates = append(candidates, field)
+		}
+	}
+	switch {
+	case len(candidates) == 1:
+		rule.PublishedField = candidates[0]
+	case len(candidates) > 1:
+		return fmt.Errorf("存在多个 datetime 字段，请通过 published_field 指定发布时间字段")
+	case rule.MaxAgeDays > 0:
+		return fmt.Errorf("max_age_days 需要一个 datetime 类型的发布时间字段")
+	}
+	return nil
+}
+
+// publishedAt 返回观测的发布时间，未配置发布时间字段或无法解析时 ok 为 false。
+func (r *DetectionRule) publishedAt(fields map[string]TypedValue) (time.Time, bool) {
+	if r.PublishedField == "" {
+		return time.Time{}, false
+	}
+	value, ok := fields[r.PublishedField]
+	if !ok || !value.Valid || value.DataType != "datetime" {
+		return time.Time{}, false
+	}
+	return value.Time, true
+}
+
+// tooOld 报告发布时间是否早于 max_age_days 天前；无法确定发布时间的条目不忽略。
+func (r *DetectionRule) tooOld(fields map[string]TypedValue, now time.Time) bool {
+	if r.MaxAgeDays <= 0 {
+		return false
+	}
+	published, ok := r.publishedAt(fields)
+	return ok && published.Before(now.AddDate(0, 0, -r.MaxAgeDays))
+}
+
+// dropStale 去掉发布时间早于 max_age_days 天前的条目，供按提取结果比较新增的 presence 检查使用。
+func (r *DetectionRule) dropStale(items []ExtractResult, now time.Time) []ExtractResult {
+	if r.MaxAgeDays <= 0 || r.PublishedField == "" {
+		return items
+	}
+	loc := r.location()
+	var recent []ExtractResult
+	for _, item := range items {
+		fields := map[string]TypedValue{r.PublishedField: NormalizeFieldIn(toString(item[r.PublishedField]), "datetime", loc)}
+		if !r.tooOld(fields, now) {
+			recent = append(recent, item)
+		}
+	}
+	return recent
+}
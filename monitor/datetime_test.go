@@ -0,0 +1,126 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func TestDateTimeFieldsParseChineseAndRelativeDates(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	now := time.Date(2026, 10, 17, 15, 42, 10, 0, shanghai)
+	cases := map[string]string{
+		"2026年10月17日":                   "2026-10-17T00:00:00+08:00",
+		"发布于 2026-10-16 09:05":          "2026-10-16T09:05:00+08:00",
+		"2026/3/5 14:30:20":             "2026-03-05T14:30:20+08:00",
+		"10-17":                         "2026-10-17T00:00:00+08:00",
+		"10-17 08:30":                   "2026-10-17T08:30:00+08:00",
+		"v3.2 发布于 2026.10.16":           "2026-10-16T00:00:00+08:00",
+		"更新：3月5日":                       "2026-03-05T00:00:00+08:00",
+		"12月30日":                        "2025-12-30T00:00:00+08:00",
+		"3小时前":                          "2026-10-17T12:00:00+08:00",
+		"5 分钟前":                         "2026-10-17T15:37:00+08:00",
+		"2天前":                           "2026-10-15T00:00:00+08:00",
+		"昨天 14:30":                      "2026-10-16T14:30:00+08:00",
+		"前天":                            "2026-10-15T00:00:00+08:00",
+		"刚刚":                            "2026-10-17T15:42:00+08:00",
+		"2 hours ago":                   "2026-10-17T13:00:00+08:00",
+		"2026-10-17T01:00:00Z":          "2026-10-17T09:00:00+08:00",
+		"Sat, 17 Oct 2026 01:00:00 GMT": "2026-10-17T09:00:00+08:00",
+		"1791939600":                    "2026-10-14T09:00:00+08:00",
+	}
+	for input, want := range cases {
+		value := normalizeDateTime(input, shanghai, now)
+		if !value.Valid || value.Value != want {
+			t.Errorf("normalizeDateTime(%q) = %+v, want %s", input, value, want)
+		}
+	}
+	for _, input := range []string{"", "明天见", "2026-02-30", "昨天 25:00", "10-17 08:61", "v3.2", "3.2 万", "1-2", "1.2.3", "第 10-12 页", "v2026.10.17"} {
+		if value := normalizeDateTime(input, shanghai, now); value.Valid {
+			t.Errorf("normalizeDateTime(%q) should be invalid, got %s", input, value.Value)
+		}
+	}
+	if value := NormalizeFieldIn("2026-10-17", "datetime", time.UTC); value.Value != "2026-10-17T00:00:00Z" {
+		t.Errorf("timezone should follow loc, got %s", value.Value)
+	}
+}
+
+func TestMaxAgeDaysIgnoresResurfacedOldItems(t *testing.T) {
+	site := &database.Site{
+		Name: "notices", URL: "https://example.com", Container: "ul", Item: "li",
+		Fields:         []database.SiteField{{Name: "title", Selector: "a"}, {Name: "date", Selector: "time"}},
+		FieldDataTypes: `{"date":"datetime"}`,
+		StrategyConfig: `{"type":"presence","identity":{"source":"source_url"},"on_first_baseline":"silent","timezone":"Asia/Shanghai","max_age_days":7}`,
+	}
+	if err := NormalizeAndValidateSiteDefinition(site); err != nil {
+		t.Fatal(err)
+	}
+	rule, err := ParseDetectionRule(site.StrategyConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.PublishedField != "date" {
+		t.Fatalf("single datetime field should become the published field, got %q", rule.PublishedField)
+	}
+
+	for _, mutate := range []func(*database.Site){
+		func(s *database.Site) { s.FieldDataTypes = `{}` },
+		func(s *database.Site) {
+			s.StrategyConfig = strings.Replace(s.StrategyConfig, "Asia/Shanghai", "Mars/Olympus", 1)
+		},
+		func(s *database.Site) { s.StrategyConfig = strings.Replace(s.StrategyConfig, `"date"`, `"title"`, 1) },
+	} {
+		invalid := *site
+		mutate(&invalid)
+		if err := NormalizeAndValidateSiteDefinition(&invalid); err == nil {
+			t.Errorf("invalid freshness config should be rejected: %s %s", invalid.FieldDataTypes, invalid.StrategyConfig)
+		}
+	}
+
+	now := time.Now()
+	loc := rule.location()
+	observe := func(key, date string) Observation {
+		return Observation{ItemKey: key, Fields: map[string]TypedValue{
+			"title": {Value: key, DataType: "text", Valid: true},
+			"date":  NormalizeFieldIn(date, "datetime", loc),
+		}}
+	}
+	detector := NewDetector("presence", *rule)
+	baseline := detector.Evaluate(SnapshotSet{}, []Observation{observe("a", "1小时前")})
+	previous := make(SnapshotSet)
+	for _, snapshot := range baseline.NextSnapshots {
+		previous[snapshot.ItemKey] = snapshot
+	}
+	old := now.AddDate(0, 0, -30).Format("2006-01-02")
+	result := detector.Evaluate(previous, []Observation{observe("a", "1小时前"), observe("old", old), observe("fresh", "2天前"), observe("undated", "")})
+	added := make(map[string]ChangeEvent)
+	for _, event := range result.Events {
+		added[event.ItemKey] = event
+	}
+	if _, ok := added["old"]; ok || len(added) != 2 {
+		t.Fatalf("old items should be ignored, got %v", result.Events)
+	}
+	if published := added["fresh"].PublishedAt; published.IsZero() || now.Sub(published) > 3*24*time.Hour {
+		t.Errorf("event should carry the parsed publish time, got %v", published)
+	}
+	if !added["undated"].PublishedAt.IsZero() {
+		t.Errorf("undated item should have no publish time")
+	}
+	if len(result.NextSnapshots) != 4 {
+		t.Errorf("old items should still be recorded in snapshots, got %d", len(result.NextSnapshots))
+	}
+
+	recent := rule.dropStale([]ExtractResult{{"title": "old", "date": old}, {"title": "new", "date": "昨天 08:00"}}, now)
+	if len(recent) != 1 || recent[0]["title"] != "new" {
+		t.Errorf("legacy presence check should drop old items, got %v", recent)
+	}
+	_, content := FormatEvent(added["fresh"], "公告")
+	if !strings.Contains(content, "发布时间: ") {
+		t.Errorf("notification should include publish time: %s", content)
+	}
+}
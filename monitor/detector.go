@@ -29,17 +29,43 @@ type ExtractionSchema struct {
 func NewDetector(ruleType string, rule DetectionRule) Detector {
 	switch ruleType {
 	case "presence":
-		return &PresenceDetector{}
+		return &PresenceDetector{rule: rule}
 	case "field_transition":
 		return NewFieldTransitionDetector(rule)
+	case "field_changed":
+		return NewFieldChangedDetector(rule)
 	default:
-		return &PresenceDetector{}
+		return &PresenceDetector{rule: rule}
 	}
 }
 
+// isSupportedStrategy 报告监控类型是否受支持。
+func isSupportedStrategy(strategyType string) bool {
+	switch strategyType {
+	case "presence", "field_transition", "field_changed":
+		return true
+	}
+	return false
+}
+
+// UsesEventEngine 报告监控类型是否由引擎基于快照检测并产生事件；presence 沿用按更新记录比较新增的流程。
+func UsesEventEngine(strategyType string) bool {
+	return strategyType == "field_transition" || strategyType == "field_changed"
+}
+
 // NormalizeAndValidateSiteDefinition 规范化并校验监控定义。
 // 创建、更新和引擎启动必须复用此入口，避免前后端校验语义漂移。
 func NormalizeAndValidateSiteDefinition(site *database.Site) error {
+	return normalizeSiteDefinition(site, false)
+}
+
+// normalizeStoredSiteDefinition 规范化已保存的监控定义。与保存时的校验相同，
+// 但升级前保存、在新语法下无效的转换规则只记录警告，提取时按旧版规则处理。
+func normalizeStoredSiteDefinition(site *database.Site) error {
+	return normalizeSiteDefinition(site, true)
+}
+
+func normalizeSiteDefinition(site *database.Site, stored bool) error {
 	if site == nil {
 		return fmt.Errorf("site is required")
 	}
@@ -50,9 +76,38 @@ func NormalizeAndValidateSiteDefinition(site *database.Site) error {
 	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
 		return fmt.Errorf("URL 仅支持 http 或 https")
 	}
-	if strings.TrimSpace(site.Container) == "" {
+	switch site.ExtractMode = strings.ToLower(strings.TrimSpace(site.ExtractMode)); site.ExtractMode {
+	case "":
+		site.ExtractMode = ExtractModeHTML
+	case ExtractModeHTML, ExtractModeJSON, ExtractModeFeed:
+	default:
+		return fmt.Errorf("不支持的提取模式: %s", site.ExtractMode)
+	}
+	if site.ExtractMode == ExtractModeFeed {
+		// 订阅源条目结构固定，容器和条目选择器不参与提取
+		site.Container, site.Item = "", ""
+		if len(site.Fields) == 0 {
+			for _, name := range feedStandardFields {
+				site.Fields = append(site.Fields, database.SiteField{Name: name, Type: "text"})
+			}
+		}
+	} else if strings.TrimSpace(site.Container) == "" {
 		return fmt.Errorf("容器选择器不能为空")
 	}
+	switch site.ExtractMode {
+	case ExtractModeJSON:
+		for _, expr := range []string{site.Container, site.Item} {
+			if _, err := compileJSONPath(expr); err != nil {
+				return err
+			}
+		}
+	case ExtractModeHTML:
+		for _, expr := range []string{site.Container, site.Item} {
+			if err := ValidateSelector(expr); err != nil {
+				return err
+			}
+		}
+	}
 	if site.StrategyType == "" {
 		site.StrategyType = "presence"
 	}
@@ -64,11 +119,12 @@ func NormalizeAndValidateSiteDefinition(site *database.Site) error {
 	if rule.Type != site.StrategyType {
 		return fmt.Errorf("strategy_type=%s 与 strategy_config.type=%s 不一致", site.StrategyType, rule.Type)
 	}
-	if rule.Type != "presence" && rule.Type != "field_transition" {
+	if !isSupportedStrategy(rule.Type) {
 		return fmt.Errorf("不支持的监控类型: %s", rule.Type)
 	}
 
 	fieldNames := make(map[string]struct{}, len(site.Fields))
+	detailFields := make(map[string]struct{})
 	schema := ExtractionSchema{Container: site.Container, Item: site.Item, Fields: make([]FieldConfig, 0, len(site.Fields))}
 	for i := range site.Fields {
 		if site.Fields[i].Type == "" {
@@ -82,9 +138,56 @@ func NormalizeAndValidateSiteDefinition(site *database.Site) error {
 		if _, exists := fieldNames[name]; exists {
 			return fmt.Errorf("字段名称重复: %s", name)
 		}
-		if field.Type != "text" && field.Type != "attr" {
+		// 详情页始终按 HTML 解析，支持全部提取类型
+		mode := site.ExtractMode
+		if strings.EqualFold(strings.TrimSpace(field.Scope), FieldScopeDetail) {
+			mode = ExtractModeHTML
+		}
+		if !isSupportedFieldType(mode, field.Type) {
 			return fmt.Errorf("字段 %s 使用了不支持的提取类型: %s", name, field.Type)
 		}
+		if _, err := compileTransform(field.Transform); err != nil {
+			if !stored {
+				return fmt.Errorf("字段 %s 的转换规则无效: %w", name, err)
+			}
+			warnLegacyTransform(site.Name, name, field.Transform, err)
+		}
+		switch site.Fields[i].Scope = strings.ToLower(strings.TrimSpace(field.Scope)); site.Fields[i].Scope {
+		case "", FieldScopeList:
+			site.Fields[i].Scope = FieldScopeList
+		case FieldScopeDetail:
+			// 详情页始终按 HTML 解析，选择器为 CSS 选择器、XPath 或结构化数据的 JSONPath
+			if err := ValidateHTMLField(FieldConfig{Selector: field.Selector, Type: field.Type, Attr: field.Attr}); err != nil {
+				return fmt.Errorf("字段 %s: %w", name, err)
+			}
+			site.Fields[i].Name = name
+			fieldNames[name] = struct{}{}
+			detailFields[name] = struct{}{}
+			schema.Fields = append(schema.Fields, FieldConfig{
+				Name: name, Selector: field.Selector, Type: field.Type, Attr: field.Attr, Transform: field.Transform,
+			})
+			continue
+		default:
+			return fmt.Errorf("字段 %s 使用了不支持的作用范围: %s", name, field.Scope)
+		}
+		switch site.ExtractMode {
+		case ExtractModeHTML:
+			if err := ValidateHTMLField(FieldConfig{Selector: field.Selector, Type: field.Type, Attr: field.Attr}); err != nil {
+				return fmt.Errorf("字段 %s: %w", name, err)
+			}
+		case ExtractModeJSON:
+			if _, err := compileJSONPath(field.Selector); err != nil {
+				return fmt.Errorf("字段 %s: %w", name, err)
+			}
+		case ExtractModeFeed:
+			source := strings.TrimSpace(field.Selector)
+			if source == "" {
+				source = name
+			}
+			if !isFeedField(source) {
+				return fmt.Errorf("订阅源字段 %s 只能读取 %s", name, strings.Join(feedStandardFields, "、"))
+			}
+		}
 		site.Fields[i].Name = name
 		fieldNames[name] = struct{}{}
 		schema.Fields = append(schema.Fields, FieldConfig{
@@ -94,6 +197,17 @@ func NormalizeAndValidateSiteDefinition(site *database.Site) error {
 	if len(fieldNames) == 0 {
 		return fmt.Errorf("至少需要配置一个提取字段")
 	}
+	if len(fieldNames) == len(detailFields) {
+		return fmt.Errorf("至少需要配置一个列表提取字段")
+	}
+	if len(detailFields) > 0 {
+		if _, ok := fieldNames["url"]; !ok {
+			return fmt.Errorf("详情页字段需要列表字段 url 提供详情页链接")
+		}
+		if _, ok := detailFields["url"]; ok {
+			return fmt.Errorf("字段 url 必须从列表提取")
+		}
+	}
 
 	dataTypes := make(map[string]string)
 	if strings.TrimSpace(site.FieldDataTypes) != "" {
@@ -109,7 +223,7 @@ func NormalizeAndValidateSiteDefinition(site *database.Site) error {
 			return fmt.Errorf("field_data_types 引用了不存在的字段: %s", field)
 		}
 		switch dataType {
-		case "text", "money", "decimal", "integer", "url":
+		case "text", "money", "decimal", "integer", "url", "datetime":
 		default:
 			return fmt.Errorf("字段 %s 使用了不支持的数据类型: %s", field, dataType)
 		}
@@ -117,6 +231,27 @@ func NormalizeAndValidateSiteDefinition(site *database.Site) error {
 	if err := validateDetectionRule(*rule, schema, fieldNames, dataTypes); err != nil {
 		return err
 	}
+	if err := resolvePublishedField(rule, dataTypes); err != nil {
+		return err
+	}
+	// 身份在抓取详情页之前生成，只能引用列表字段
+	for _, field := range append([]string{rule.Identity.Field}, rule.Identity.Fields...) {
+		if _, ok := detailFields[field]; ok {
+			return fmt.Errorf("identity 字段不能使用详情页字段: %s", field)
+		}
+	}
+
+	fetchConfig, err := ParseFetchConfig(site.FetchConfig)
+	if err != nil {
+		return err
+	}
+	if fetchConfig.Pagination != nil && fetchConfig.Pagination.NextSelector != "" && site.ExtractMode != ExtractModeHTML {
+		return fmt.Errorf("下一页选择器仅支持 HTML 提取模式，请改用 url_template")
+	}
+	canonicalFetchConfig, err := fetchConfig.Canonical()
+	if err != nil {
+		return err
+	}
 
 	canonicalRule, err := json.Marshal(rule)
 	if err != nil {
@@ -128,6 +263,7 @@ func NormalizeAndValidateSiteDefinition(site *database.Site) error {
 	}
 	site.StrategyConfig = string(canonicalRule)
 	site.FieldDataTypes = string(canonicalDataTypes)
+	site.FetchConfig = canonicalFetchConfig
 	return nil
 }
 
@@ -163,6 +299,9 @@ func validateDetectionRule(rule DetectionRule, schema ExtractionSchema, fieldNam
 	if rule.Type == "presence" {
 		return nil
 	}
+	if rule.Type == "field_changed" {
+		return validateWatchConfig(rule, fieldNames)
+	}
 	if len(rule.Conditions) != 1 {
 		return fmt.Errorf("field_transition 当前必须配置且只能配置一个条件")
 	}
@@ -10,7 +10,9 @@ import (
 )
 
 // PresenceDetector 检测新增条目
-type PresenceDetector struct{}
+type PresenceDetector struct {
+	rule DetectionRule
+}
 
 func (d *PresenceDetector) Validate(schema ExtractionSchema, config json.RawMessage) error {
 	rule, err := ParseDetectionRule(string(config))
@@ -57,17 +59,20 @@ func (d *PresenceDetector) Evaluate(previous SnapshotSet, current []Observation)
 			ns.DefinitionVersion = existing.DefinitionVersion
 		} else {
 			ns.FirstSeenAt = now
-			if len(previous) > 0 {
+			// 重新出现在列表中的旧条目（发布时间早于 max_age_days）只记录快照，不产生事件
+			if len(previous) > 0 && !d.rule.tooOld(obs.Fields, now) {
 				title := extractStr(payload, "title")
 				urlStr := extractStr(payload, "url")
+				published, _ := d.rule.publishedAt(obs.Fields)
 				events = append(events, ChangeEvent{
-					EventType:  "item_added",
-					ItemKey:    itemKey,
-					Title:      title,
-					URL:        urlStr,
-					After:      payload,
-					NewValue:   title,
-					OccurredAt: now,
+					EventType:   "item_added",
+					ItemKey:     itemKey,
+					Title:       title,
+					URL:         urlStr,
+					After:       payload,
+					NewValue:    title,
+					OccurredAt:  now,
+					PublishedAt: published,
 				})
 			}
 		}
@@ -163,7 +168,7 @@ func (d *FieldTransitionDetector) Evaluate(previous SnapshotSet, current []Obser
 				}
 
 				eventType := d.matchingEventType(existing.PriceMinor, priceInfo.minor, priceInfo.currency)
-				if eventType != "" {
+				if eventType != "" && !d.rule.tooOld(obs.Fields, now) {
 					decrease := existing.PriceMinor - priceInfo.minor
 					percent := float64(decrease) / float64(existing.PriceMinor) * 100
 
@@ -175,6 +180,7 @@ func (d *FieldTransitionDetector) Evaluate(previous SnapshotSet, current []Obser
 						title = itemKey
 					}
 					urlStr := extractStr(payload, "url")
+					published, _ := d.rule.publishedAt(obs.Fields)
 
 					events = append(events, ChangeEvent{
 						EventType:     eventType,
@@ -189,6 +195,7 @@ func (d *FieldTransitionDetector) Evaluate(previous SnapshotSet, current []Obser
 						ChangePercent: math.Round(percent*100) / 100,
 						Currency:      priceInfo.currency,
 						OccurredAt:    now,
+						PublishedAt:   published,
 					})
 				}
 			} else if !priceInfo.valid {
@@ -0,0 +1,206 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cn-maul/Gentry/database"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxCheckDiagnostics 每个站点保留的检查诊断数量
+	maxCheckDiagnostics = 100
+	// hitRateBaselineChecks 计算命中率基线时参考的最近检查次数，minHitRateBaselineChecks 为告警所需的最少历史
+	hitRateBaselineChecks    = 10
+	minHitRateBaselineChecks = 3
+	// hitRateDropThreshold 命中率比基线低至少 50 个百分点时视为下降
+	hitRateDropThreshold = 0.5
+)
+
+// FieldHitStat 一次提取中单个字段的命中情况。
+type FieldHitStat struct {
+	Name string `json:"name"`
+	// Items 参与统计的条目数；详情页字段只统计本次抓取了详情页的条目
+	Items   int `json:"items"`
+	Matched int `json:"matched"`
+	// Empty 选择器未匹配或值为空（包括转换管道输出为空）
+	Empty int `json:"empty"`
+	// ParseFailed 值不为空，但无法按 field_data_types 解析（如金额、时间）
+	ParseFailed int     `json:"parse_failed"`
+	HitRate     float64 `json:"hit_rate"`
+	// BaselineHitRate 同一定义版本最近检查的平均命中率，历史不足时为空
+	BaselineHitRate *float64 `json:"baseline_hit_rate,omitempty"`
+	// Degraded 命中率相对基线大幅下降
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+// fieldHitStats 统计 items 中各字段的命中情况，items 为空时返回 nil。
+func fieldHitStats(items []ExtractResult, names []string, dataTypes map[string]string) []FieldHitStat {
+	if len(items) == 0 {
+		return nil
+	}
+	stats := make([]FieldHitStat, 0, len(names))
+	for _, name := range names {
+		stat := FieldHitStat{Name: name, Items: len(items)}
+		dataType := fieldDataType(dataTypes, name)
+		for _, item := range items {
+			value := strings.TrimSpace(toString(item[name]))
+			switch {
+			case value == "":
+				stat.Empty++
+			case dataType != "text" && !NormalizeField(value, dataType).Valid:
+				stat.ParseFailed++
+			default:
+				stat.Matched++
+			}
+		}
+		stat.HitRate = float64(stat.Matched) / float64(stat.Items)
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// siteFieldHitStats 统计列表字段和详情页字段；detailItems 为本次抓取了详情页的条目。
+func siteFieldHitStats(site *database.Site, items, detailItems []ExtractResult, dataTypes map[string]string) []FieldHitStat {
+	var listNames []string
+	for _, field := range listSelectors(site).Fields {
+		listNames = append(listNames, field.Name)
+	}
+	stats := fieldHitStats(items, listNames, dataTypes)
+	return append(stats, fieldHitStats(detailItems, detailFieldNames(site.Fields), dataTypes)...)
+}
+
+// siteFieldDataTypes 读取站点配置的字段数据类型，配置无效时按文本处理。
+func siteFieldDataTypes(site *database.Site) map[string]string {
+	dataTypes := make(map[string]string)
+	if strings.TrimSpace(site.FieldDataTypes) != "" {
+		_ = json.Unmarshal([]byte(site.FieldDataTypes), &dataTypes)
+	}
+	return dataTypes
+}
+
+// recordFieldHitStats 与同一定义版本最近的检查比较命中率并保存本次统计；
+// 字段命中率从正常变为大幅下降时创建 field_degraded 告警事件，持续下降期间不重复告警。
+// 诊断只用于排查，失败只记录日志，不影响本次检查。
+func recordFieldHitStats(site *database.Site, itemCount int, stats []FieldHitStat) []FieldHitStat {
+	history, err := database.ListCheckDiagnostics(site.ID, site.ConfigVersion, hitRateBaselineChecks)
+	if err != nil {
+		log.Printf("[%s] %v", site.Name, err)
+		return stats
+	}
+	var previous []map[string]FieldHitStat
+	for _, diagnostic := range history {
+		var fields []FieldHitStat
+		if err := json.Unmarshal([]byte(diagnostic.FieldsJSON), &fields); err != nil {
+			continue
+		}
+		byName := make(map[string]FieldHitStat, len(fields))
+		for _, field := range fields {
+			byName[field.Name] = field
+		}
+		previous = append(previous, byName)
+	}
+
+	var degraded []FieldHitStat
+	for i := range stats {
+		// 已下降的检查不计入基线，避免基线随故障一起下滑后停止告警
+		var sum float64
+		var count int
+		for _, fields := range previous {
+			if field, ok := fields[stats[i].Name]; ok && field.Items > 0 && !field.Degraded {
+				sum += field.HitRate
+				count++
+			}
+		}
+		if count < minHitRateBaselineChecks {
+			continue
+		}
+		baseline := sum / float64(count)
+		stats[i].BaselineHitRate = &baseline
+		if baseline-stats[i].HitRate < hitRateDropThreshold {
+			continue
+		}
+		stats[i].Degraded = true
+		if last, ok := previous[0][stats[i].Name]; !ok || !last.Degraded {
+			degraded = append(degraded, stats[i])
+		}
+	}
+
+	fieldsJSON, _ := json.Marshal(stats)
+	diagnostic := &database.CheckDiagnostic{
+		SiteID:            site.ID,
+		DefinitionVersion: site.ConfigVersion,
+		ItemCount:         itemCount,
+		FieldsJSON:        string(fieldsJSON),
+	}
+	if err := database.SaveCheckDiagnostic(diagnostic, maxCheckDiagnostics); err != nil {
+		log.Printf("[%s] %v", site.Name, err)
+		return stats
+	}
+	for _, stat := range degraded {
+		log.Printf("[%s] 字段 %s 命中率从 %.0f%% 下降到 %.0f%%", site.Name, stat.Name, *stat.BaselineHitRate*100, stat.HitRate*100)
+		if err := createFieldDegradedEvent(site, diagnostic.ID, stat); err != nil {
+			log.Printf("[%s] 创建字段命中率告警失败: %v", site.Name, err)
+		}
+	}
+	return stats
+}
+
+// createFieldDegradedEvent 写入字段命中率下降事件并为站点的推送账户创建投递任务。
+func createFieldDegradedEvent(site *database.Site, diagnosticID uint, stat FieldHitStat) error {
+	now := time.Now()
+	event := &database.MonitorEvent{
+		SiteID:            site.ID,
+		EventType:         "field_degraded",
+		ItemKey:           stat.Name,
+		Title:             fmt.Sprintf("字段 %s 命中率下降", stat.Name),
+		URL:               site.URL,
+		OldValue:          formatHitRate(*stat.BaselineHitRate),
+		NewValue:          formatHitRate(stat.HitRate),
+		DedupeKey:         GenerateDedupeKey(site.ID, site.ConfigVersion, "field_degraded", stat.Name, "", fmt.Sprint(diagnosticID)),
+		DefinitionVersion: site.ConfigVersion,
+		OccurredAt:        now,
+	}
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		return createEventTx(tx, event, site.GetNotifyAccountIDs())
+	})
+}
+
+func formatHitRate(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+// CheckDiagnosticRecord 一次检查的字段命中记录。
+type CheckDiagnosticRecord struct {
+	ID                uint           `json:"id"`
+	CreatedAt         time.Time      `json:"created_at"`
+	DefinitionVersion int            `json:"definition_version"`
+	ItemCount         int            `json:"item_count"`
+	Fields            []FieldHitStat `json:"fields"`
+}
+
+// ListFieldDiagnostics 按时间倒序返回站点最近的字段命中记录。
+func ListFieldDiagnostics(siteID uint, limit int) ([]CheckDiagnosticRecord, error) {
+	diagnostics, err := database.ListCheckDiagnostics(siteID, 0, limit)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]CheckDiagnosticRecord, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		record := CheckDiagnosticRecord{
+			ID:                diagnostic.ID,
+			CreatedAt:         diagnostic.CreatedAt,
+			DefinitionVersion: diagnostic.DefinitionVersion,
+			ItemCount:         diagnostic.ItemCount,
+		}
+		if err := json.Unmarshal([]byte(diagnostic.FieldsJSON), &record.Fields); err != nil {
+			return nil, fmt.Errorf("解析检查诊断 %d 失败: %w", diagnostic.ID, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
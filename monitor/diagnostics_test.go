@@ -0,0 +1,115 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func TestFieldHitStats(t *testing.T) {
+	items := []ExtractResult{
+		{"title": "商品 A", "price": "¥10.00"},
+		{"title": "商品 B", "price": "暂无报价"},
+		{"title": " ", "price": "¥12.00"},
+		{"price": "¥13.00"},
+	}
+	stats := fieldHitStats(items, []string{"title", "price"}, map[string]string{"price": "money"})
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 field stats, got %+v", stats)
+	}
+	title, price := stats[0], stats[1]
+	if title.Items != 4 || title.Matched != 2 || title.Empty != 2 || title.ParseFailed != 0 || title.HitRate != 0.5 {
+		t.Errorf("unexpected title stat: %+v", title)
+	}
+	if price.Matched != 3 || price.Empty != 0 || price.ParseFailed != 1 || price.HitRate != 0.75 {
+		t.Errorf("unexpected price stat: %+v", price)
+	}
+	if fieldHitStats(nil, []string{"title"}, nil) != nil {
+		t.Error("no items should produce no stats")
+	}
+}
+
+func TestRecordFieldHitStatsAlertsOnceOnSharpDrop(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	site := createPriceMonitorSite(t)
+
+	healthy := []FieldHitStat{{Name: "price", Items: 10, Matched: 10, HitRate: 1}}
+	for i := 0; i < minHitRateBaselineChecks; i++ {
+		stats := recordFieldHitStats(site, 10, append([]FieldHitStat(nil), healthy...))
+		if stats[0].Degraded {
+			t.Fatalf("healthy check %d marked degraded: %+v", i, stats[0])
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		stats := recordFieldHitStats(site, 10, []FieldHitStat{{Name: "price", Items: 10, Matched: 2, Empty: 8, HitRate: 0.2}})
+		if !stats[0].Degraded || stats[0].BaselineHitRate == nil || *stats[0].BaselineHitRate != 1 {
+			t.Fatalf("sharp drop should be degraded against a healthy baseline: %+v", stats[0])
+		}
+	}
+
+	var events []database.MonitorEvent
+	if err := database.GetDB().Where("site_id = ? AND event_type = ?", site.ID, "field_degraded").Find(&events).Error; err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected a single alert while the field stays degraded, got %d", len(events))
+	}
+	if events[0].ItemKey != "price" || events[0].OldValue != "100%" || events[0].NewValue != "20%" || events[0].DeliveryStatus != "skipped" {
+		t.Fatalf("unexpected alert event: %+v", events[0])
+	}
+
+	records, err := ListFieldDiagnostics(site.ID, 10)
+	if err != nil {
+		t.Fatalf("list diagnostics: %v", err)
+	}
+	if len(records) != 5 || records[0].Fields[0].Matched != 2 || records[0].ItemCount != 10 {
+		t.Fatalf("unexpected diagnostics history: %+v", records)
+	}
+}
+
+func TestCheckOnceRecordsFieldStats(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><h1>测试商品</h1><span class="price">¥123.45</span></body></html>`))
+	}))
+	defer server.Close()
+	site := createPriceMonitorSite(t)
+	site.URL = server.URL
+
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, _, err := engine.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("check once: %v", err)
+	}
+	stats := engine.FieldStats()
+	if len(stats) != 2 || stats[0].Name != "title" || stats[1].Name != "price" || stats[1].HitRate != 1 {
+		t.Fatalf("unexpected field stats: %+v", stats)
+	}
+	records, err := ListFieldDiagnostics(site.ID, 10)
+	if err != nil {
+		t.Fatalf("list diagnostics: %v", err)
+	}
+	if len(records) != 1 || records[0].ItemCount != 1 || len(records[0].Fields) != 2 {
+		t.Fatalf("unexpected diagnostics history: %+v", records)
+	}
+}
+
+func TestKeywordFilterDoesNotSkipFieldDegradedAlerts(t *testing.T) {
+	site := &database.Site{NotifyFilter: "keyword", NotifyKeywords: "面试,录用"}
+	alert := ChangeEvent{EventType: "field_degraded", Title: "字段 price 命中率下降", OldValue: "100%", NewValue: "20%"}
+	if !passesKeywordFilter(site, alert) {
+		t.Error("field_degraded alerts should bypass keyword filtering")
+	}
+	if passesKeywordFilter(site, ChangeEvent{EventType: "item_added", Title: "运动会通知"}) {
+		t.Error("content events without keywords should still be filtered")
+	}
+	if !passesKeywordFilter(site, ChangeEvent{EventType: "item_added", Title: "面试名单公示"}) {
+		t.Error("content events with keywords should pass")
+	}
+}
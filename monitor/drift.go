@@ -0,0 +1,357 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+// 选择器漂移错误：页面改版后列表选择器不再命中，或身份字段整体失效
+var (
+	ErrNoItems           = errors.New("提取结果为空，请检查选择器")
+	ErrIdentityMissing   = errors.New("身份字段为空，无法生成稳定标识")
+	ErrIdentityDuplicate = errors.New("身份字段重复")
+)
+
+const (
+	// maxRepairKeywords 作为扫描关键词的最近条目标题数量
+	maxRepairKeywords = 10
+	// maxRepairSamples 修复建议保存的样例条目数量
+	maxRepairSamples = 5
+	// minRepairMatchRatio 候选配置提取的条目中身份与基线一致的最低比例，低于该比例时应用修复后重建基线
+	minRepairMatchRatio = 0.5
+)
+
+// isSelectorDrift 报告提取错误是否表示选择器漂移。
+func isSelectorDrift(err error) bool {
+	return errors.Is(err, ErrNoItems) || errors.Is(err, ErrIdentityMissing) || errors.Is(err, ErrIdentityDuplicate)
+}
+
+// repairMatcher 统计候选配置提取的条目中身份与现有基线一致的数量，返回需要保留的快照 item_key。
+type repairMatcher func(candidate *database.Site, items []ExtractResult) (int, []string, error)
+
+// repairRescanInterval 扫描未找到候选后，同一定义版本再次扫描前的等待时间
+const repairRescanInterval = 24 * time.Hour
+
+// proposeSelectorRepair 用最近条目标题作为关键词对新页面运行智能扫描，
+// 选出身份匹配最多的候选生成修复建议。同一定义版本已有待处理建议时直接返回它；
+// 建议被忽略后不再重新生成，扫描未找到候选时 repairRescanInterval 内不再重复扫描。
+// 修复建议只用于提示，失败只记录日志。
+func proposeSelectorRepair(site *database.Site, html, reason string, keywords []string, match repairMatcher) *database.SelectorRepair {
+	if site.ExtractMode != "" && site.ExtractMode != ExtractModeHTML {
+		return nil
+	}
+	existing, err := database.FindSelectorRepair(site.ID, site.ConfigVersion)
+	if err != nil {
+		log.Printf("[%s] %v", site.Name, err)
+		return nil
+	}
+	if existing != nil {
+		if existing.Status == "pending" {
+			return existing
+		}
+		if existing.Status != "unavailable" || time.Since(existing.UpdatedAt) < repairRescanInterval {
+			return nil
+		}
+	}
+
+	scan, err := smartScanHTMLWithSettings(html, &ScanSettings{URL: site.URL, Keywords: keywords})
+	if err != nil {
+		log.Printf("[%s] 选择器漂移扫描失败: %v", site.Name, err)
+		return nil
+	}
+	var best *database.SelectorRepair
+	for _, container := range scan.Containers {
+		candidate, ok := repairCandidateSite(site, container.Config)
+		if !ok {
+			continue
+		}
+		items, err := NewExtractor(listSelectors(candidate)).Extract(html)
+		if err != nil || len(items) == 0 {
+			continue
+		}
+		if err := ResolveExtractedURLs(site.URL, items); err != nil {
+			continue
+		}
+		matched, keepKeys, err := match(candidate, items)
+		if err != nil {
+			continue
+		}
+		if best != nil && matched <= best.MatchedItems {
+			continue
+		}
+		best = newSelectorRepair(site, candidate, container.Strategy, reason, items, matched, keepKeys)
+	}
+	if best == nil {
+		log.Printf("[%s] 选择器漂移：未找到可用的替代选择器", site.Name)
+		if err := database.MarkSelectorRepairUnavailable(site.ID, site.ConfigVersion, reason); err != nil {
+			log.Printf("[%s] %v", site.Name, err)
+		}
+		return nil
+	}
+	repair, err := database.CreateSelectorRepair(best)
+	if err != nil {
+		log.Printf("[%s] %v", site.Name, err)
+		return nil
+	}
+	log.Printf("[%s] 选择器漂移：已生成修复建议 #%d（容器 %s，%d 条中 %d 条身份与基线一致）", site.Name, repair.ID, repair.Container, repair.ItemCount, repair.MatchedItems)
+	return repair
+}
+
+// repairCandidateSite 用扫描候选替换容器、条目和同名列表字段的选择器，保留原字段的转换管道；
+// 候选没有的字段和详情页字段保持不变。候选不覆盖任何现有字段或配置无效时返回 false。
+func repairCandidateSite(site *database.Site, config ScanMonitorConfig) (*database.Site, bool) {
+	if config.ExtractMode != "" && config.ExtractMode != ExtractModeHTML {
+		return nil, false
+	}
+	scanned := make(map[string]ScanFieldConfig, len(config.Fields))
+	for _, field := range config.Fields {
+		scanned[field.Name] = field
+	}
+	candidate := *site
+	candidate.Container = config.Container
+	candidate.Item = config.Item
+	candidate.Fields = make([]database.SiteField, 0, len(site.Fields))
+	replaced := 0
+	for _, field := range site.Fields {
+		if suggestion, ok := scanned[field.Name]; ok && field.Scope != FieldScopeDetail {
+			field.ID = 0
+			field.Selector = suggestion.Selector
+			field.Type = suggestion.Type
+			field.Attr = suggestion.Attr
+			if field.Transform == "" {
+				field.Transform = suggestion.Transform
+			}
+			replaced++
+		}
+		candidate.Fields = append(candidate.Fields, field)
+	}
+	if replaced == 0 {
+		return nil, false
+	}
+	if err := NormalizeAndValidateSiteDefinition(&candidate); err != nil {
+		return nil, false
+	}
+	return &candidate, true
+}
+
+func newSelectorRepair(site, candidate *database.Site, strategy, reason string, items []ExtractResult, matched int, keepKeys []string) *database.SelectorRepair {
+	fields := make([]ScanFieldConfig, 0, len(candidate.Fields))
+	for _, field := range candidate.Fields {
+		fields = append(fields, ScanFieldConfig{Name: field.Name, Selector: field.Selector, Type: field.Type, Attr: field.Attr, Transform: field.Transform})
+	}
+	samples := items
+	if len(samples) > maxRepairSamples {
+		samples = samples[:maxRepairSamples]
+	}
+	fieldsJSON, _ := json.Marshal(fields)
+	keepKeysJSON, _ := json.Marshal(keepKeys)
+	samplesJSON, _ := json.Marshal(samples)
+	return &database.SelectorRepair{
+		SiteID:            site.ID,
+		DefinitionVersion: site.ConfigVersion,
+		Reason:            truncateRunes(reason, 255),
+		Strategy:          strategy,
+		Container:         candidate.Container,
+		Item:              candidate.Item,
+		FieldsJSON:        string(fieldsJSON),
+		ItemCount:         len(items),
+		MatchedItems:      matched,
+		KeepKeysJSON:      string(keepKeysJSON),
+		SamplesJSON:       string(samplesJSON),
+	}
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
+
+// snapshotKeywords 返回最近出现的快照标题，作为漂移扫描的关键词。
+func snapshotKeywords(snapshots SnapshotSet) []string {
+	list := make([]Snapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		list = append(list, snapshot)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].LastSeenAt.Equal(list[j].LastSeenAt) {
+			return list[i].LastSeenAt.After(list[j].LastSeenAt)
+		}
+		return list[i].ItemKey < list[j].ItemKey
+	})
+	var titles []ExtractResult
+	for _, snapshot := range list {
+		titles = append(titles, ExtractResult{"title": snapshot.Payload["title"]})
+	}
+	return titleKeywords(titles)
+}
+
+// titleKeywords 取条目中前 maxRepairKeywords 个不重复的非空标题。
+func titleKeywords(items []ExtractResult) []string {
+	seen := make(map[string]bool)
+	var keywords []string
+	for _, item := range items {
+		title := strings.TrimSpace(toString(item["title"]))
+		if title == "" || seen[title] {
+			continue
+		}
+		seen[title] = true
+		keywords = append(keywords, title)
+		if len(keywords) >= maxRepairKeywords {
+			break
+		}
+	}
+	return keywords
+}
+
+// snapshotMatcher 按新配置计算条目身份，与快照 item_key 比较。
+func snapshotMatcher(snapshots SnapshotSet) repairMatcher {
+	return func(candidate *database.Site, items []ExtractResult) (int, []string, error) {
+		engine, err := NewEngine(candidate)
+		if err != nil {
+			return 0, nil, err
+		}
+		observations, err := engine.mergePages([][]ExtractResult{items})
+		if err != nil {
+			return 0, nil, err
+		}
+		var keepKeys []string
+		for _, observation := range observations {
+			if _, ok := snapshots[observation.ItemKey]; ok {
+				keepKeys = append(keepKeys, observation.ItemKey)
+			}
+		}
+		sort.Strings(keepKeys)
+		return len(keepKeys), keepKeys, nil
+	}
+}
+
+// historyMatcher 用于 presence 模式：与已记录条目按标题和链接比较，已记录条目本身不随版本删除。
+func historyMatcher(last []ExtractResult) repairMatcher {
+	known := make(map[string]bool, len(last))
+	for _, item := range last {
+		known[extractKey(item)] = true
+	}
+	return func(_ *database.Site, items []ExtractResult) (int, []string, error) {
+		matched := 0
+		for _, item := range items {
+			if key := extractKey(item); key != "" && known[key] {
+				matched++
+			}
+		}
+		return matched, nil, nil
+	}
+}
+
+// SelectorRepairView 修复建议的 API 视图。
+type SelectorRepairView struct {
+	ID                uint              `json:"id"`
+	CreatedAt         time.Time         `json:"created_at"`
+	DefinitionVersion int               `json:"definition_version"`
+	Status            string            `json:"status"`
+	Reason            string            `json:"reason"`
+	Strategy          string            `json:"strategy"`
+	Container         string            `json:"container"`
+	Item              string            `json:"item"`
+	Fields            []ScanFieldConfig `json:"fields"`
+	ItemCount         int               `json:"item_count"`
+	MatchedItems      int               `json:"matched_items"`
+	Samples           []ExtractResult   `json:"samples"`
+}
+
+// ListSelectorRepairs 按时间倒序返回站点的修复建议。
+func ListSelectorRepairs(siteID uint, limit int) ([]SelectorRepairView, error) {
+	repairs, err := database.ListSelectorRepairs(siteID, limit)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]SelectorRepairView, 0, len(repairs))
+	for _, repair := range repairs {
+		view := SelectorRepairView{
+			ID:                repair.ID,
+			CreatedAt:         repair.CreatedAt,
+			DefinitionVersion: repair.DefinitionVersion,
+			Status:            repair.Status,
+			Reason:            repair.Reason,
+			Strategy:          repair.Strategy,
+			Container:         repair.Container,
+			Item:              repair.Item,
+			ItemCount:         repair.ItemCount,
+			MatchedItems:      repair.MatchedItems,
+		}
+		if err := json.Unmarshal([]byte(repair.FieldsJSON), &view.Fields); err != nil {
+			return nil, fmt.Errorf("解析修复建议 %d 失败: %w", repair.ID, err)
+		}
+		if repair.SamplesJSON != "" {
+			_ = json.Unmarshal([]byte(repair.SamplesJSON), &view.Samples)
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// RepairedDefinition 根据修复建议构建新定义，返回需要保留的快照 item_key。
+// 新定义推进配置版本；由引擎检测的监控匹配比例达到 minRepairMatchRatio 时保留快照、基线仍可用，
+// 否则丢弃旧快照并重建基线，避免未匹配的条目在修复后被当作新增推送。
+func RepairedDefinition(site database.Site, repair *database.SelectorRepair) (database.Site, []string, error) {
+	if repair.Status != "pending" {
+		return site, nil, fmt.Errorf("修复建议已处理: %s", repair.Status)
+	}
+	if repair.DefinitionVersion != site.ConfigVersion {
+		return site, nil, fmt.Errorf("监控配置已变化，修复建议已失效")
+	}
+	var fields []ScanFieldConfig
+	if err := json.Unmarshal([]byte(repair.FieldsJSON), &fields); err != nil {
+		return site, nil, fmt.Errorf("解析修复建议失败: %w", err)
+	}
+	var keepKeys []string
+	if repair.KeepKeysJSON != "" {
+		if err := json.Unmarshal([]byte(repair.KeepKeysJSON), &keepKeys); err != nil {
+			return site, nil, fmt.Errorf("解析修复建议失败: %w", err)
+		}
+	}
+	// 字段按名称套用建议，作用范围等其余属性沿用当前配置
+	suggested := make(map[string]ScanFieldConfig, len(fields))
+	for _, field := range fields {
+		suggested[field.Name] = field
+	}
+	candidate := site
+	candidate.Container = repair.Container
+	candidate.Item = repair.Item
+	candidate.Fields = make([]database.SiteField, 0, len(site.Fields))
+	for _, field := range site.Fields {
+		if suggestion, ok := suggested[field.Name]; ok {
+			field.Selector = suggestion.Selector
+			field.Type = suggestion.Type
+			field.Attr = suggestion.Attr
+			field.Transform = suggestion.Transform
+		}
+		field.ID = 0
+		candidate.Fields = append(candidate.Fields, field)
+	}
+	if err := NormalizeAndValidateSiteDefinition(&candidate); err != nil {
+		return site, nil, fmt.Errorf("修复后的配置无效: %w", err)
+	}
+	candidate.ConfigVersion++
+	// 匹配比例过低时保留的快照太少，其余条目会在修复后被当作新增推送，改为重建基线
+	if repair.ItemCount == 0 || float64(repair.MatchedItems) < minRepairMatchRatio*float64(repair.ItemCount) {
+		keepKeys = nil
+	}
+	if UsesEventEngine(candidate.StrategyType) {
+		if len(keepKeys) > 0 {
+			candidate.BaselineStatus = "ready"
+		} else {
+			candidate.BaselineStatus = "needs_baseline"
+		}
+	}
+	return candidate, keepKeys, nil
+}
@@ -0,0 +1,298 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+const driftOldLayout = `<html><body><ul class="news">
+<li><a href="/n/1">关于举办春季运动会的通知</a></li>
+<li><a href="/n/2">图书馆开放时间调整公告</a></li>
+</ul></body></html>`
+
+const driftNewLayout = `<html><body><div class="list-v2">
+<div class="entry"><h3><a href="/n/3">新学期选课安排</a></h3><span class="date">2026-10-17</span></div>
+<div class="entry"><h3><a href="/n/1">关于举办春季运动会的通知</a></h3><span class="date">2026-10-16</span></div>
+<div class="entry"><h3><a href="/n/2">图书馆开放时间调整公告</a></h3><span class="date">2026-10-15</span></div>
+</div></body></html>`
+
+func TestCheckOnceProposesSelectorRepairOnDrift(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var redesigned atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if redesigned.Load() {
+			_, _ = w.Write([]byte(driftNewLayout))
+			return
+		}
+		_, _ = w.Write([]byte(driftOldLayout))
+	}))
+	defer server.Close()
+
+	site := &database.Site{
+		Name:           "drift-news",
+		URL:            server.URL,
+		Container:      ".news",
+		Item:           "li",
+		StrategyType:   "presence",
+		StrategyConfig: `{"type":"presence","identity":{"field":"url"},"on_first_baseline":"silent"}`,
+		ConfigVersion:  1,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+		},
+	}
+	if err := database.CreateSiteWithFields(site); err != nil {
+		t.Fatalf("create site: %v", err)
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, _, err := engine.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("baseline check: %v", err)
+	}
+
+	redesigned.Store(true)
+	_, _, err = engine.CheckOnce(context.Background())
+	if !errors.Is(err, ErrNoItems) || !strings.Contains(err.Error(), "修复建议") {
+		t.Fatalf("expected drift error with repair hint, got %v", err)
+	}
+	// 同一定义版本重复失败时不重复生成建议
+	if _, _, err := engine.CheckOnce(context.Background()); !errors.Is(err, ErrNoItems) {
+		t.Fatalf("expected drift error, got %v", err)
+	}
+	repairs, err := ListSelectorRepairs(site.ID, 10)
+	if err != nil {
+		t.Fatalf("list repairs: %v", err)
+	}
+	if len(repairs) != 1 {
+		t.Fatalf("expected one pending repair, got %+v", repairs)
+	}
+	repair := repairs[0]
+	if repair.ItemCount != 3 || repair.MatchedItems != 2 || repair.Status != "pending" {
+		t.Fatalf("unexpected repair: %+v", repair)
+	}
+
+	stored, err := database.LoadSelectorRepair(site.ID, repair.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("load repair: %v", err)
+	}
+	var current database.Site
+	if err := database.GetDB().Preload("Fields").First(&current, site.ID).Error; err != nil {
+		t.Fatalf("load site: %v", err)
+	}
+	repaired, keepKeys, err := RepairedDefinition(current, stored)
+	if err != nil {
+		t.Fatalf("build repaired definition: %v", err)
+	}
+	if len(keepKeys) != 2 || repaired.ConfigVersion != 2 || repaired.BaselineStatus != "ready" {
+		t.Fatalf("unexpected repaired definition: version=%d status=%s keep=%v", repaired.ConfigVersion, repaired.BaselineStatus, keepKeys)
+	}
+	// 加载之后配置被其他请求修改时拒绝应用，且不消耗修复建议
+	if err := database.GetDB().Model(&database.Site{}).Where("id = ?", site.ID).Update("config_version", 5).Error; err != nil {
+		t.Fatalf("simulate concurrent edit: %v", err)
+	}
+	if err := database.ApplySelectorRepair(&repaired, repaired.Fields, stored.ID, current.ConfigVersion, keepKeys); !errors.Is(err, database.ErrSiteModified) {
+		t.Fatalf("expected concurrent edit to be detected, got %v", err)
+	}
+	if err := database.GetDB().Model(&database.Site{}).Where("id = ?", site.ID).Update("config_version", 1).Error; err != nil {
+		t.Fatalf("restore config version: %v", err)
+	}
+	if err := database.ApplySelectorRepair(&repaired, repaired.Fields, stored.ID, current.ConfigVersion, keepKeys); err != nil {
+		t.Fatalf("apply repair: %v", err)
+	}
+	if err := database.ApplySelectorRepair(&repaired, repaired.Fields, stored.ID, current.ConfigVersion, keepKeys); err == nil {
+		t.Fatal("an accepted repair should not apply twice")
+	}
+
+	var updated database.Site
+	if err := database.GetDB().Preload("Fields").First(&updated, site.ID).Error; err != nil {
+		t.Fatalf("reload site: %v", err)
+	}
+	snapshots, err := LoadSnapshots(updated.ID, updated.ConfigVersion)
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("expected matching snapshots to survive the repair, got %d (%v)", len(snapshots), err)
+	}
+	engine, err = NewEngine(&updated)
+	if err != nil {
+		t.Fatalf("create repaired engine: %v", err)
+	}
+	events, isFirst, err := engine.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("check after repair: %v", err)
+	}
+	if isFirst || len(events) != 1 || !strings.HasSuffix(events[0].ItemKey, "/n/3") {
+		t.Fatalf("expected only the new post to be reported, got first=%v events=%+v", isFirst, events)
+	}
+}
+
+func TestRepairedDefinitionRejectsStaleRepair(t *testing.T) {
+	site := database.Site{ConfigVersion: 3}
+	if _, _, err := RepairedDefinition(site, &database.SelectorRepair{Status: "pending", DefinitionVersion: 2}); err == nil {
+		t.Fatal("expected a repair for an older definition to be rejected")
+	}
+	if _, _, err := RepairedDefinition(site, &database.SelectorRepair{Status: "dismissed", DefinitionVersion: 3}); err == nil {
+		t.Fatal("expected a dismissed repair to be rejected")
+	}
+}
+
+func TestRepairedDefinitionRebuildsBaselineOnLowMatchRatio(t *testing.T) {
+	site := database.Site{
+		Name:           "low-match",
+		URL:            "https://example.com/news",
+		Container:      ".news",
+		Item:           "li",
+		StrategyType:   "field_changed",
+		StrategyConfig: `{"type":"field_changed","identity":{"field":"url"},"watch":{"fields":["title"]}}`,
+		ConfigVersion:  1,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+		},
+	}
+	repair := &database.SelectorRepair{
+		Status:            "pending",
+		DefinitionVersion: 1,
+		Container:         ".list-v2",
+		Item:              ".entry",
+		FieldsJSON:        `[{"name":"title","selector":"h3 a","type":"text"},{"name":"url","selector":"h3 a","type":"attr","attr":"href"}]`,
+		KeepKeysJSON:      `["https://example.com/n/1"]`,
+		ItemCount:         20,
+		MatchedItems:      1,
+	}
+	repaired, keepKeys, err := RepairedDefinition(site, repair)
+	if err != nil {
+		t.Fatalf("build repaired definition: %v", err)
+	}
+	if len(keepKeys) != 0 || repaired.BaselineStatus != "needs_baseline" {
+		t.Fatalf("expected a low match ratio to rebuild the baseline, got status=%s keep=%v", repaired.BaselineStatus, keepKeys)
+	}
+}
+
+func TestMergePagesReportsIdentityDrift(t *testing.T) {
+	site := &database.Site{
+		Name: "drift-identity", URL: "https://example.com/list", Container: "ul", Item: "li",
+		StrategyType: "presence", StrategyConfig: `{"type":"presence","identity":{"field":"url"}}`,
+		Fields: []database.SiteField{{Name: "url", Selector: "a", Type: "attr", Attr: "href"}},
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	_, err = engine.mergePages([][]ExtractResult{{{"url": "/same"}, {"url": "/same"}}})
+	if !errors.Is(err, ErrIdentityDuplicate) || !isSelectorDrift(err) {
+		t.Fatalf("expected identity duplicate drift, got %v", err)
+	}
+	if _, err := engine.mergePages(nil); !errors.Is(err, ErrNoItems) {
+		t.Fatalf("expected empty extraction drift, got %v", err)
+	}
+}
+
+func TestDismissedSelectorRepairIsNotRecreated(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var redesigned atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if redesigned.Load() {
+			_, _ = w.Write([]byte(driftNewLayout))
+			return
+		}
+		_, _ = w.Write([]byte(driftOldLayout))
+	}))
+	defer server.Close()
+
+	site := &database.Site{
+		Name: "drift-dismiss", URL: server.URL, Container: ".news", Item: "li",
+		StrategyType:   "presence",
+		StrategyConfig: `{"type":"presence","identity":{"field":"url"},"on_first_baseline":"silent"}`,
+		ConfigVersion:  1,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+		},
+	}
+	if err := database.CreateSiteWithFields(site); err != nil {
+		t.Fatalf("create site: %v", err)
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, _, err := engine.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("baseline check: %v", err)
+	}
+	redesigned.Store(true)
+	if _, _, err := engine.CheckOnce(context.Background()); !errors.Is(err, ErrNoItems) {
+		t.Fatalf("expected drift error, got %v", err)
+	}
+	repairs, err := ListSelectorRepairs(site.ID, 10)
+	if err != nil || len(repairs) != 1 {
+		t.Fatalf("expected one repair, got %+v (%v)", repairs, err)
+	}
+	if err := database.DismissSelectorRepair(repairs[0].ID); err != nil {
+		t.Fatalf("dismiss repair: %v", err)
+	}
+
+	_, _, err = engine.CheckOnce(context.Background())
+	if !errors.Is(err, ErrNoItems) || strings.Contains(err.Error(), "修复建议") {
+		t.Fatalf("expected plain drift error after dismissal, got %v", err)
+	}
+	repairs, err = ListSelectorRepairs(site.ID, 10)
+	if err != nil || len(repairs) != 1 || repairs[0].Status != "dismissed" {
+		t.Fatalf("dismissed repair should not be recreated, got %+v (%v)", repairs, err)
+	}
+}
+
+func TestSelectorRepairScanWithoutCandidateIsNotRepeated(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	site := &database.Site{Name: "drift-empty", URL: "https://example.com/news", ConfigVersion: 1}
+	if err := database.CreateSiteWithFields(site); err != nil {
+		t.Fatalf("create site: %v", err)
+	}
+	noMatch := func(*database.Site, []ExtractResult) (int, []string, error) { return 0, nil, nil }
+	propose := func() {
+		t.Helper()
+		if repair := proposeSelectorRepair(site, `<html><body><p>维护中</p></body></html>`, "未提取到条目", nil, noMatch); repair != nil {
+			t.Fatalf("expected no repair for an empty page, got %+v", repair)
+		}
+	}
+	loadMarker := func() *database.SelectorRepair {
+		t.Helper()
+		marker, err := database.FindSelectorRepair(site.ID, site.ConfigVersion)
+		if err != nil || marker == nil || marker.Status != "unavailable" {
+			t.Fatalf("expected an unavailable marker, got %+v (%v)", marker, err)
+		}
+		return marker
+	}
+
+	propose()
+	first := loadMarker()
+	stale := first.UpdatedAt.Add(-time.Hour)
+	if err := database.GetDB().Model(first).UpdateColumn("updated_at", stale).Error; err != nil {
+		t.Fatalf("age marker: %v", err)
+	}
+	// 无候选标记未过期时不重新扫描，标记时间保持不变
+	propose()
+	if marker := loadMarker(); !marker.UpdatedAt.Equal(stale) {
+		t.Fatalf("expected the scan to be skipped, marker refreshed at %v", marker.UpdatedAt)
+	}
+	expired := time.Now().Add(-repairRescanInterval - time.Hour)
+	if err := database.GetDB().Model(first).UpdateColumn("updated_at", expired).Error; err != nil {
+		t.Fatalf("expire marker: %v", err)
+	}
+	propose()
+	if marker := loadMarker(); marker.ID != first.ID || !marker.UpdatedAt.After(expired) {
+		t.Fatalf("expected the expired marker to be rescanned and refreshed, got %+v", marker)
+	}
+	repairs, err := ListSelectorRepairs(site.ID, 10)
+	if err != nil || len(repairs) != 0 {
+		t.Fatalf("unavailable markers should not be listed, got %+v (%v)", repairs, err)
+	}
+}
@@ -20,11 +20,16 @@ var ErrStaleDefinition = errors.New("monitor definition changed during check")
 
 // Engine 监控引擎，编排一次检查的完整流程
 type Engine struct {
-	site      *database.Site
-	extractor *Extractor
-	fetcher   *fetcher.Fetcher
-	detector  Detector
-	rule      *DetectionRule
+	site            *database.Site
+	extractor       *Extractor
+	detailExtractor *Extractor
+	source          fetcher.Source
+	fetchConfig     *FetchConfig
+	detector        Detector
+	rule            *DetectionRule
+	location        *time.Location
+	// fieldStats 最近一次 CheckOnce 的字段命中情况
+	fieldStats []FieldHitStat
 }
 
 // NewEngine 创建新引擎，返回错误而不是在非法配置下默默运行
@@ -34,67 +39,82 @@ func NewEngine(site *database.Site) (*Engine, error) {
 	}
 	normalizedSite := *site
 	normalizedSite.Fields = append([]database.SiteField(nil), site.Fields...)
-	if err := NormalizeAndValidateSiteDefinition(&normalizedSite); err != nil {
+	if err := normalizeStoredSiteDefinition(&normalizedSite); err != nil {
 		return nil, fmt.Errorf("invalid monitor definition: %w", err)
 	}
 	site = &normalizedSite
 
 	f := fetcher.New()
-	selectors := SiteSelectors{
-		Container: site.Container,
-		Item:      site.Item,
-		Fields:    make([]FieldConfig, len(site.Fields)),
-	}
-	for i, f := range site.Fields {
-		selectors.Fields[i] = FieldConfig{
-			Name:      f.Name,
-			Selector:  f.Selector,
-			Type:      f.Type,
-			Attr:      f.Attr,
-			Transform: f.Transform,
-		}
-	}
+	selectors := listSelectors(site)
 
 	rule, err := ParseDetectionRule(site.StrategyConfig)
 	if err != nil {
 		return nil, fmt.Errorf("parse detection rule failed: %w", err)
 	}
 
+	fetchConfig, err := ParseFetchConfig(site.FetchConfig)
+	if err != nil {
+		return nil, fmt.Errorf("parse fetch config failed: %w", err)
+	}
+
 	// 未知策略类型返回错误
-	if rule.Type != "presence" && rule.Type != "field_transition" {
+	if !isSupportedStrategy(rule.Type) {
 		return nil, fmt.Errorf("unknown strategy type: %s", rule.Type)
 	}
 
 	detector := NewDetector(rule.Type, *rule)
 
 	return &Engine{
-		site:      site,
-		extractor: NewExtractor(selectors),
-		fetcher:   f,
-		detector:  detector,
-		rule:      rule,
+		site:            site,
+		extractor:       NewExtractor(selectors),
+		detailExtractor: detailExtractor(site.Fields),
+		source:          fetchConfig.siteSource(site, f),
+		fetchConfig:     fetchConfig,
+		detector:        detector,
+		rule:            rule,
+		location:        rule.location(),
 	}, nil
 }
 
 // CheckOnce 执行一次完整检查，返回事件和是否建立基线
 func (e *Engine) CheckOnce(ctx context.Context) ([]ChangeEvent, bool, error) {
 	site := e.site
-	observations, err := e.observe(ctx)
+	state, err := database.LoadFetchState(site.ID, site.ConfigVersion)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := e.fetch(ctx, state)
 	if err != nil {
 		return nil, false, err
 	}
+	// 页面未变化：视为成功检查，跳过提取和快照写入
+	if resp.NotModified() {
+		return nil, false, nil
+	}
+	observations, err := e.observeResponse(ctx, resp, newPageArchiver(site, e.fetchConfig))
+	if err != nil {
+		if isSelectorDrift(err) {
+			err = e.reportDrift(resp.Body, err)
+		}
+		return nil, false, err
+	}
 
 	// 1. 加载快照
 	snapshots, err := LoadSnapshots(site.ID, site.ConfigVersion)
 	if err != nil {
 		return nil, false, fmt.Errorf("load snapshots failed: %w", err)
 	}
+	// 静默建立基线时不抓取详情页，之后新增或变化的条目再补充详情字段
+	isFirstBaseline := len(snapshots) == 0
+	var detailItems []ExtractResult
+	if !isFirstBaseline || e.rule.OnFirstBaseline != "silent" {
+		detailItems = e.enrich(ctx, observations, snapshots)
+	}
 
 	// 2. 检测
 	result := e.detector.Evaluate(snapshots, observations)
 
 	// 3. 首次基线处理
-	isFirstBaseline := len(snapshots) == 0
 	if isFirstBaseline && e.rule.OnFirstBaseline == "silent" {
 		result.Events = nil
 	}
@@ -114,7 +134,12 @@ func (e *Engine) CheckOnce(ctx context.Context) ([]ChangeEvent, bool, error) {
 		}
 		result.Events[i].SiteID = site.ID
 		result.Events[i].DefinitionVersion = site.ConfigVersion
-		result.Events[i].DedupeKey = GenerateDedupeKey(site.ID, site.ConfigVersion, result.Events[i].EventType, result.Events[i].ItemKey, beforeFP, afterFP)
+		// 同一条目的多个字段同时变化时各自产生事件，字段名参与去重
+		dedupeItem := result.Events[i].ItemKey
+		if result.Events[i].Field != "" {
+			dedupeItem += "\x00" + result.Events[i].Field
+		}
+		result.Events[i].DedupeKey = GenerateDedupeKey(site.ID, site.ConfigVersion, result.Events[i].EventType, dedupeItem, beforeFP, afterFP)
 	}
 
 	// 5. 事务性持久化
@@ -122,52 +147,133 @@ func (e *Engine) CheckOnce(ctx context.Context) ([]ChangeEvent, bool, error) {
 	if err := PersistEvaluation(site, isFirstBaseline, result, accountIDs); err != nil {
 		return nil, false, fmt.Errorf("persist evaluation failed: %w", err)
 	}
+	saveFetchValidators(site, resp)
+	items := observationItems(observations)
+	e.fieldStats = recordFieldHitStats(site, len(observations), siteFieldHitStats(site, items, detailItems, e.parseFieldDataTypes()))
 
 	return result.Events, isFirstBaseline, nil
 }
 
-func (e *Engine) observe(ctx context.Context) ([]Observation, error) {
-	site := e.site
-	html, err := e.fetcher.FetchContext(ctx, site.URL)
+// reportDrift 在已有基线时为选择器漂移生成修复建议，并在错误中提示建议编号。
+func (e *Engine) reportDrift(html string, driftErr error) error {
+	snapshots, err := LoadSnapshots(e.site.ID, e.site.ConfigVersion)
+	if err != nil || len(snapshots) == 0 {
+		return driftErr
+	}
+	repair := proposeSelectorRepair(e.site, html, driftErr.Error(), snapshotKeywords(snapshots), snapshotMatcher(snapshots))
+	if repair == nil {
+		return driftErr
+	}
+	return fmt.Errorf("%w（已生成选择器修复建议 #%d）", driftErr, repair.ID)
+}
+
+// FieldStats 返回最近一次 CheckOnce 的字段命中情况，页面未变化时为空。
+func (e *Engine) FieldStats() []FieldHitStat {
+	return e.fieldStats
+}
+
+// observationItems 取出观测的原始字段；详情页字段已由 enrich 合并或从快照沿用。
+func observationItems(observations []Observation) []ExtractResult {
+	items := make([]ExtractResult, len(observations))
+	for i, observation := range observations {
+		items[i] = observation.Raw
+	}
+	return items
+}
+
+// fetch 抓取站点页面；state 非空时携带上次的校验值发起条件请求。
+func (e *Engine) fetch(ctx context.Context, state *database.FetchState) (*fetcher.Response, error) {
+	req := e.fetchConfig.Request(e.site.URL)
+	if state != nil {
+		req.IfNoneMatch = state.ETag
+		req.IfModifiedSince = state.LastModified
+	}
+	resp, err := e.source.Do(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("fetch failed: %w", err)
 	}
+	return resp, nil
+}
 
-	rawResults, err := e.extractor.Extract(html)
+func (e *Engine) observe(ctx context.Context) ([]Observation, error) {
+	resp, err := e.fetch(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("extraction failed: %w", err)
-	}
-	if err := ResolveExtractedURLs(site.URL, rawResults); err != nil {
-		return nil, fmt.Errorf("resolve URLs failed: %w", err)
+		return nil, err
 	}
+	return e.observeResponse(ctx, resp, nil)
+}
 
-	observations := e.toObservations(rawResults)
-	if len(observations) == 0 {
-		return nil, fmt.Errorf("提取结果为空，请检查选择器")
+// observeResponse 从首页响应开始提取（含分页），合并所有页的观测后统一校验身份。
+func (e *Engine) observeResponse(ctx context.Context, resp *fetcher.Response, record pageRecorder) ([]Observation, error) {
+	pages, err := extractPages(ctx, e.source, e.fetchConfig, e.extractor, e.site.URL, resp, record)
+	if err != nil {
+		return nil, err
 	}
+	return e.mergePages(pages)
+}
 
+// mergePages 将各页提取结果转换为观测并校验身份。
+func (e *Engine) mergePages(pages [][]ExtractResult) ([]Observation, error) {
+	var observations []Observation
+	// 列表在翻页期间可能整体后移，同一条目会同时出现在相邻两页；
+	// 跨页且内容一致的重复条目只保留首次出现，其他重复视为身份配置错误。
+	type firstOccurrence struct {
+		page        int
+		fingerprint string
+	}
+	firstSeen := make(map[string]firstOccurrence)
 	identityCounts := make(map[string]int)
-	for _, obs := range observations {
-		if obs.ItemKey == "" {
-			return nil, fmt.Errorf("身份字段为空，无法生成稳定标识")
+	for pageIndex, rawResults := range pages {
+		for _, obs := range e.toObservations(rawResults) {
+			if obs.ItemKey == "" {
+				return nil, ErrIdentityMissing
+			}
+			fingerprint := ComputeFingerprint(obs.Raw)
+			if seen, exists := firstSeen[obs.ItemKey]; exists {
+				if seen.page != pageIndex && seen.fingerprint == fingerprint {
+					continue
+				}
+			} else {
+				firstSeen[obs.ItemKey] = firstOccurrence{page: pageIndex, fingerprint: fingerprint}
+			}
+			identityCounts[obs.ItemKey]++
+			observations = append(observations, obs)
 		}
-		identityCounts[obs.ItemKey]++
+	}
+	if len(observations) == 0 {
+		return nil, ErrNoItems
 	}
 	for key, count := range identityCounts {
 		if count > 1 {
-			return nil, fmt.Errorf("身份字段重复: %s (%d次)", key, count)
+			return nil, fmt.Errorf("%w: %s (%d次)", ErrIdentityDuplicate, key, count)
 		}
 	}
 	return observations, nil
 }
 
+// validationDetailSamples 验证配置时最多抓取的详情页数量
+const validationDetailSamples = 3
+
 // ValidateExtraction 只读验证抓取、选择器、身份和价格解析，不写入任何状态。
 func (e *Engine) ValidateExtraction(ctx context.Context) (*ExtractionValidationResult, error) {
 	observations, err := e.observe(ctx)
 	if err != nil {
 		return nil, err
 	}
-	report := &ExtractionValidationResult{ExtractedItems: len(observations)}
+	// 验证只为前几个条目抓取详情页，其余条目计入跳过数量
+	sampled := observations
+	if e.detailExtractor != nil && len(sampled) > validationDetailSamples {
+		sampled = sampled[:validationDetailSamples]
+	}
+	detailItems := e.enrich(ctx, sampled, nil)
+	items := observationItems(observations)
+	report := &ExtractionValidationResult{
+		ExtractedItems: len(observations),
+		Fields:         siteFieldHitStats(e.site, items, detailItems, e.parseFieldDataTypes()),
+	}
+	if e.detailExtractor != nil {
+		report.DetailSkipped = len(observations) - len(sampled)
+	}
 	limit := len(observations)
 	if limit > 5 {
 		limit = 5
@@ -207,6 +313,15 @@ func (e *Engine) ValidateExtraction(ctx context.Context) (*ExtractionValidationR
 	return report, nil
 }
 
+// saveFetchValidators 在检查结果落库后记录响应校验值，失败只影响下次是否走条件请求。
+func saveFetchValidators(site *database.Site, resp *fetcher.Response) {
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if err := database.SaveFetchState(site.ID, site.ConfigVersion, etag, lastModified); err != nil {
+		log.Printf("[%s] 保存抓取校验值失败: %v", site.Name, err)
+	}
+}
+
 // PersistEvaluation 事务性持久化快照、事件和投递任务
 func PersistEvaluation(site *database.Site, isFirstBaseline bool, result EvaluationResult, accountIDs []uint) error {
 	if site == nil {
@@ -239,10 +354,6 @@ func PersistEvaluation(site *database.Site, isFirstBaseline bool, result Evaluat
 				eventURL = site.URL
 			}
 
-			deliveryStatus := "pending"
-			if len(accountIDs) == 0 {
-				deliveryStatus = "skipped"
-			}
 			monitorEvent := &database.MonitorEvent{
 				SiteID:            siteID,
 				EventType:         event.EventType,
@@ -259,30 +370,14 @@ func PersistEvaluation(site *database.Site, isFirstBaseline bool, result Evaluat
 				DedupeKey:         event.DedupeKey,
 				DefinitionVersion: configVersion,
 				OccurredAt:        event.OccurredAt,
-				DeliveryStatus:    deliveryStatus,
+				FieldName:         event.Field,
 			}
-			createResult := tx.Clauses(clause.OnConflict{
-				Columns:   []clause.Column{{Name: "site_id"}, {Name: "dedupe_key"}},
-				DoNothing: true,
-			}).Create(monitorEvent)
-			if createResult.Error != nil {
-				return fmt.Errorf("create event failed: %w", createResult.Error)
+			if !event.PublishedAt.IsZero() {
+				publishedAt := event.PublishedAt
+				monitorEvent.PublishedAt = &publishedAt
 			}
-			if createResult.RowsAffected == 0 {
-				continue
-			}
-
-			// 为每个账户创建投递任务
-			for _, accountID := range accountIDs {
-				delivery := &database.NotificationDelivery{
-					EventID:   monitorEvent.ID,
-					AccountID: accountID,
-					SiteID:    siteID,
-					Status:    "pending",
-				}
-				if err := tx.Create(delivery).Error; err != nil {
-					return fmt.Errorf("create delivery failed: %w", err)
-				}
+			if err := createEventTx(tx, monitorEvent, accountIDs); err != nil {
+				return err
 			}
 		}
 
@@ -303,6 +398,37 @@ func PersistEvaluation(site *database.Site, isFirstBaseline bool, result Evaluat
 	})
 }
 
+// createEventTx 按去重键写入事件并为每个推送账户创建投递任务，没有推送账户时事件标记为 skipped。
+// 去重键已存在时不做任何修改。
+func createEventTx(tx *gorm.DB, event *database.MonitorEvent, accountIDs []uint) error {
+	event.DeliveryStatus = "pending"
+	if len(accountIDs) == 0 {
+		event.DeliveryStatus = "skipped"
+	}
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "site_id"}, {Name: "dedupe_key"}},
+		DoNothing: true,
+	}).Create(event)
+	if result.Error != nil {
+		return fmt.Errorf("create event failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	for _, accountID := range accountIDs {
+		delivery := &database.NotificationDelivery{
+			EventID:   event.ID,
+			AccountID: accountID,
+			SiteID:    event.SiteID,
+			Status:    "pending",
+		}
+		if err := tx.Create(delivery).Error; err != nil {
+			return fmt.Errorf("create delivery failed: %w", err)
+		}
+	}
+	return nil
+}
+
 // saveSnapshotsTx 事务内 upsert 快照
 func saveSnapshotsTx(tx *gorm.DB, siteID uint, snapshots []Snapshot, configVersion int) error {
 	if len(snapshots) == 0 {
@@ -358,20 +484,22 @@ func (e *Engine) toObservations(results []ExtractResult) []Observation {
 		raw := make(map[string]interface{})
 
 		for k, v := range item {
-			strVal := fmt.Sprintf("%v", v)
+			strVal := fieldValueString(v)
 			raw[k] = v
 			dataType := "text"
 			if dt, ok := dataTypes[k]; ok {
 				dataType = dt
 			}
-			fields[k] = NormalizeField(strVal, dataType)
+			fields[k] = NormalizeFieldIn(strVal, dataType, e.location)
 		}
 
 		// 生成 item key。presence 的默认 source_url 规则需要对列表条目使用
 		// 提取 URL、标题或内容指纹，否则整页条目会共享同一个 key。
 		itemKey := GenerateItemKey(item, e.rule.Identity, e.site.URL)
 		if e.rule.Type == "presence" && e.rule.Identity.Source == "source_url" {
-			if extractedURL := strings.TrimSpace(fmt.Sprint(item["url"])); extractedURL != "" && extractedURL != "<nil>" {
+			if guid := strings.TrimSpace(fmt.Sprint(item["guid"])); e.site.ExtractMode == ExtractModeFeed && guid != "" && guid != "<nil>" {
+				itemKey = guid
+			} else if extractedURL := strings.TrimSpace(fmt.Sprint(item["url"])); extractedURL != "" && extractedURL != "<nil>" {
 				itemKey = extractedURL
 			} else if title := strings.TrimSpace(fmt.Sprint(item["title"])); title != "" && title != "<nil>" {
 				itemKey = title
@@ -469,7 +597,7 @@ func processDelivery(d database.NotificationDelivery) {
 
 	// 获取站点
 	var site database.Site
-	if err := database.GetDB().First(&site, d.SiteID).Error; err != nil {
+	if err := database.GetDB().Preload("Fields").First(&site, d.SiteID).Error; err != nil {
 		failDelivery(d.ID, "site not found: "+err.Error())
 		return
 	}
@@ -500,6 +628,15 @@ func processDelivery(d database.NotificationDelivery) {
 		ChangeAmount:  event.ChangeAmount,
 		ChangePercent: event.ChangePercent,
 		Currency:      event.Currency,
+		Field:         event.FieldName,
+		Details:       eventDetails(detailFieldNames(site.Fields), event.AfterJSON),
+	}
+	if event.PublishedAt != nil {
+		// 数据库读出的时间不带原时区，按站点配置的时区展示
+		changeEvent.PublishedAt = *event.PublishedAt
+		if rule, err := ParseDetectionRule(site.StrategyConfig); err == nil {
+			changeEvent.PublishedAt = changeEvent.PublishedAt.In(rule.location())
+		}
 	}
 
 	// source_url 回退：事件 URL 为空时使用站点 URL
@@ -512,13 +649,11 @@ func processDelivery(d database.NotificationDelivery) {
 	title, content := FormatEvent(changeEvent, site.Name)
 
 	// 关键词过滤
-	if site.NotifyFilter == "keyword" && site.NotifyKeywords != "" {
-		if !matchEventKeywords(changeEvent, site.NotifyKeywords) {
-			if err := skipDelivery(d.ID); err != nil {
-				log.Printf("[DeliveryWorker] 标记 skipped 失败 delivery=%d: %v", d.ID, err)
-			}
-			return
+	if !passesKeywordFilter(&site, changeEvent) {
+		if err := skipDelivery(d.ID); err != nil {
+			log.Printf("[DeliveryWorker] 标记 skipped 失败 delivery=%d: %v", d.ID, err)
 		}
+		return
 	}
 
 	// 发送
@@ -694,33 +829,78 @@ func PendingDeliveries(limit int) ([]database.NotificationDelivery, error) {
 
 // FormatEvent 格式化事件为通知文本
 func FormatEvent(event ChangeEvent, siteName string) (string, string) {
+	var title, content string
 	switch event.EventType {
 	case "item_added":
-		title := fmt.Sprintf("%s 有新内容", siteName)
-		content := fmt.Sprintf("标题: %s\n链接: %s", event.Title, event.URL)
-		return title, content
+		title = fmt.Sprintf("%s 有新内容", siteName)
+		content = fmt.Sprintf("标题: %s\n链接: %s", event.Title, event.URL)
 	case "price_dropped":
-		title := fmt.Sprintf("降价提醒: %s", event.Title)
-		content := fmt.Sprintf("商品: %s\n原价: %s\n现价: %s\n降价: %s (%.2f%%)\n链接: %s",
+		title = fmt.Sprintf("降价提醒: %s", event.Title)
+		content = fmt.Sprintf("商品: %s\n原价: %s\n现价: %s\n降价: %s (%.2f%%)\n链接: %s",
 			event.Title, event.OldValue, event.NewValue,
 			formatPrice(event.ChangeAmount, event.Currency), event.ChangePercent,
 			event.URL)
-		return title, content
+	case "field_degraded":
+		title = fmt.Sprintf("%s 提取异常", siteName)
+		content = fmt.Sprintf("%s\n基线命中率: %s\n本次命中率: %s\n请检查选择器是否失效\n链接: %s",
+			event.Title, event.OldValue, event.NewValue, event.URL)
+	case "field_changed":
+		title = fmt.Sprintf("%s 字段变化: %s", siteName, event.Title)
+		content = fmt.Sprintf("条目: %s\n字段: %s\n原值: %s\n新值: %s\n链接: %s",
+			event.Title, event.Field, event.OldValue, event.NewValue, event.URL)
 	case "price_target_reached":
-		title := fmt.Sprintf("到价提醒: %s", event.Title)
-		content := fmt.Sprintf("商品: %s\n之前价格: %s\n当前价格: %s\n价格已进入目标范围\n链接: %s",
+		title = fmt.Sprintf("到价提醒: %s", event.Title)
+		content = fmt.Sprintf("商品: %s\n之前价格: %s\n当前价格: %s\n价格已进入目标范围\n链接: %s",
 			event.Title, event.OldValue, event.NewValue, event.URL)
-		return title, content
 	default:
-		title := fmt.Sprintf("%s 有更新", siteName)
-		content := fmt.Sprintf("事件: %s\n商品: %s\n链接: %s", event.EventType, event.Title, event.URL)
-		return title, content
+		title = fmt.Sprintf("%s 有更新", siteName)
+		content = fmt.Sprintf("事件: %s\n商品: %s\n链接: %s", event.EventType, event.Title, event.URL)
+	}
+	if !event.PublishedAt.IsZero() {
+		content += "\n发布时间: " + event.PublishedAt.Format("2006-01-02 15:04")
+	}
+	for _, detail := range event.Details {
+		content += fmt.Sprintf("\n%s: %s", detail.Name, detail.Value)
+	}
+	return title, content
+}
+
+// eventDetails 从事件快照中取出详情页字段，缺失或为空的字段跳过。
+func eventDetails(names []string, afterJSON string) []EventDetail {
+	if len(names) == 0 || afterJSON == "" {
+		return nil
+	}
+	var after map[string]interface{}
+	if err := json.Unmarshal([]byte(afterJSON), &after); err != nil {
+		return nil
+	}
+	var details []EventDetail
+	for _, name := range names {
+		if value := toString(after[name]); value != "" {
+			details = append(details, EventDetail{Name: name, Value: value})
+		}
 	}
+	return details
+}
+
+// systemEventTypes 系统告警事件，与页面内容无关
+var systemEventTypes = map[string]bool{"field_degraded": true}
+
+// passesKeywordFilter 判断事件是否通过站点的关键词过滤，系统告警事件不受关键词过滤影响。
+func passesKeywordFilter(site *database.Site, event ChangeEvent) bool {
+	if site.NotifyFilter != "keyword" || site.NotifyKeywords == "" || systemEventTypes[event.EventType] {
+		return true
+	}
+	return matchEventKeywords(event, site.NotifyKeywords)
 }
 
 func matchEventKeywords(event ChangeEvent, keywords string) bool {
 	kwList := strings.Split(keywords, ",")
-	text := strings.ToLower(event.Title + " " + event.NewValue)
+	text := event.Title + " " + event.NewValue
+	for _, detail := range event.Details {
+		text += " " + detail.Value
+	}
+	text = strings.ToLower(text)
 	for _, kw := range kwList {
 		kw = strings.TrimSpace(kw)
 		if kw == "" {
@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -85,6 +86,9 @@ func TestValidateExtractionReturnsPriceSamples(t *testing.T) {
 	if sample.Raw != "¥123.45" || sample.Normalized != "¥123.45" || sample.Currency != "CNY" {
 		t.Fatalf("unexpected price sample: %+v", sample)
 	}
+	if len(report.Fields) != 2 || report.Fields[1].Name != "price" || report.Fields[1].Matched != 1 {
+		t.Fatalf("unexpected field stats: %+v", report.Fields)
+	}
 }
 
 func TestValidateExtractionSupportsPresenceLists(t *testing.T) {
@@ -288,3 +292,249 @@ func TestCheckNowSerializesConcurrentChecks(t *testing.T) {
 		t.Errorf("fetches must be serialized, max concurrent fetches = %d", max)
 	}
 }
+
+func TestValidateExtractionAppliesFetchConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		cookie, err := r.Cookie("session")
+		if r.Method != http.MethodPost || r.Header.Get("X-Token") != "abc" || err != nil || cookie.Value != "s1" || string(body) != "page=1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><h1>测试商品</h1><span class="price">¥99.00</span></body></html>`))
+	}))
+	defer server.Close()
+
+	site := &database.Site{
+		Name: "fetch-config", URL: server.URL, Container: "body", StrategyType: "field_transition",
+		StrategyConfig: `{"type":"field_transition","identity":{"source":"source_url"},"conditions":[{"field":"price","value_type":"money","operator":"decreased"}],"on_first_baseline":"silent"}`,
+		FieldDataTypes: `{"price":"money"}`,
+		FetchConfig:    `{"method":"post","headers":{"x-token":"abc"},"cookies":{"session":"s1"},"body":"page=1"}`,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "h1", Type: "text"},
+			{Name: "price", Selector: ".price", Type: "text"},
+		},
+	}
+	if err := NormalizeAndValidateSiteDefinition(site); err != nil {
+		t.Fatalf("normalize site: %v", err)
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	report, err := engine.ValidateExtraction(context.Background())
+	if err != nil {
+		t.Fatalf("validate extraction: %v", err)
+	}
+	if report.ExtractedItems != 1 {
+		t.Fatalf("unexpected validation report: %+v", report)
+	}
+}
+
+func TestNormalizeRejectsInvalidFetchConfig(t *testing.T) {
+	cases := []string{
+		`{"method":"DELETE"}`,
+		`{"body":"a=1"}`,
+		`{"headers":{"Host":"evil.example"}}`,
+		`{"headers":{"X-A":"1\r\nX-B: 2"}}`,
+		`{"cookies":{"a":"b;c=d"}}`,
+		`{"charset":"klingon"}`,
+	}
+	for _, fetchConfig := range cases {
+		site := &database.Site{
+			Name: "fetch-config-invalid", URL: "https://example.com/product/1", Container: "body", StrategyType: "field_transition",
+			StrategyConfig: `{"type":"field_transition","identity":{"source":"source_url"},"conditions":[{"field":"price","value_type":"money","operator":"decreased"}],"on_first_baseline":"silent"}`,
+			FieldDataTypes: `{"price":"money"}`,
+			FetchConfig:    fetchConfig,
+			Fields: []database.SiteField{
+				{Name: "title", Selector: "h1", Type: "text"},
+				{Name: "price", Selector: ".price", Type: "text"},
+			},
+		}
+		if err := NormalizeAndValidateSiteDefinition(site); err == nil {
+			t.Fatalf("expected fetch config %s to be rejected", fetchConfig)
+		}
+	}
+}
+
+func TestCheckOnceSkipsUnchangedPage(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`<html><body><h1>商品</h1><span class="price">¥100.00</span></body></html>`))
+	}))
+	defer server.Close()
+
+	site := createPriceMonitorSite(t)
+	site.URL = server.URL
+	if err := database.GetDB().Model(site).Update("url", server.URL).Error; err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, isFirstBaseline, err := engine.CheckOnce(context.Background()); err != nil || !isFirstBaseline {
+		t.Fatalf("first check: baseline=%v err=%v", isFirstBaseline, err)
+	}
+	var before database.MonitorSnapshot
+	if err := database.GetDB().Where("site_id = ?", site.ID).First(&before).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	events, isFirstBaseline, err := engine.CheckOnce(context.Background())
+	if err != nil || isFirstBaseline || len(events) != 0 {
+		t.Fatalf("unchanged check: events=%v baseline=%v err=%v", events, isFirstBaseline, err)
+	}
+	if got := atomic.LoadInt32(&notModified); got != 1 {
+		t.Fatalf("expected one conditional 304 response, got %d", got)
+	}
+	var after database.MonitorSnapshot
+	if err := database.GetDB().Where("site_id = ?", site.ID).First(&after).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !after.LastSeenAt.Equal(before.LastSeenAt) {
+		t.Fatalf("304 must not rewrite snapshots: %v -> %v", before.LastSeenAt, after.LastSeenAt)
+	}
+
+	// 重置基线后定义版本变化，旧校验值失效，必须重新完整抓取。
+	version, err := database.ResetMonitorBaseline(site.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	site.ConfigVersion = version
+	engine, err = NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, isFirstBaseline, err := engine.CheckOnce(context.Background()); err != nil || !isFirstBaseline {
+		t.Fatalf("check after reset: baseline=%v err=%v", isFirstBaseline, err)
+	}
+	if got := atomic.LoadInt32(&notModified); got != 1 {
+		t.Fatalf("stale validators must not be sent after reset, got %d", got)
+	}
+}
+
+func TestValidateExtractionTranscodesGBKPages(t *testing.T) {
+	// "公告" 的 GBK 编码
+	gbkTitle := "\xb9\xab\xb8\xe6"
+	pages := map[string]string{
+		"/meta":     `<html><head><meta charset="gb2312"></head><body><ul><li><a href="/a">` + gbkTitle + `</a></li></ul></body></html>`,
+		"/override": `<html><body><ul><li><a href="/a">` + gbkTitle + `</a></li></ul></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(pages[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		path        string
+		fetchConfig string
+	}{
+		{path: "/meta"},
+		{path: "/override", fetchConfig: `{"charset":"GBK"}`},
+	}
+	for _, tc := range cases {
+		site := &database.Site{
+			Name: "gbk" + tc.path, URL: server.URL + tc.path, Container: "ul", Item: "li", StrategyType: "presence",
+			StrategyConfig: `{"type":"presence","identity":{"source":"source_url"},"on_first_baseline":"silent"}`,
+			FetchConfig:    tc.fetchConfig,
+			Fields: []database.SiteField{
+				{Name: "title", Selector: "a", Type: "text"},
+			},
+		}
+		engine, err := NewEngine(site)
+		if err != nil {
+			t.Fatalf("create engine: %v", err)
+		}
+		report, err := engine.ValidateExtraction(context.Background())
+		if err != nil {
+			t.Fatalf("validate %s: %v", tc.path, err)
+		}
+		if len(report.Samples) != 1 || report.Samples[0].Raw != "公告" {
+			t.Fatalf("%s: expected transcoded title, got %+v", tc.path, report.Samples)
+		}
+	}
+}
+
+func TestArchivedPagesReplayWithoutSideEffects(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var price atomic.Value
+	price.Store("¥80.00")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><h1>商品</h1><span class="price">` + price.Load().(string) + `</span></body></html>`))
+	}))
+	defer server.Close()
+
+	site := createPriceMonitorSite(t)
+	site.URL = server.URL
+	site.FetchConfig = `{"archive":{"max_count":2}}`
+	if err := database.GetDB().Model(site).Updates(map[string]interface{}{"url": site.URL, "fetch_config": site.FetchConfig}).Error; err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, _, err := engine.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("first check: %v", err)
+	}
+	price.Store("¥100.00")
+	if _, _, err := engine.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("second check: %v", err)
+	}
+
+	archives, err := database.ListPageArchives(site.ID, 10)
+	if err != nil || len(archives) != 2 {
+		t.Fatalf("expected two archives, got %d err=%v", len(archives), err)
+	}
+	oldest, err := database.LoadPageArchive(site.ID, archives[1].ID)
+	if err != nil || oldest == nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	result, err := ReplayArchive(site, oldest)
+	if err != nil {
+		t.Fatalf("replay archive: %v", err)
+	}
+	if result.ExtractedItems != 1 || len(result.Events) != 1 || result.Events[0].EventType != "price_dropped" {
+		t.Fatalf("unexpected replay result: %+v", result)
+	}
+	var eventCount int64
+	database.GetDB().Model(&database.MonitorEvent{}).Where("site_id = ?", site.ID).Count(&eventCount)
+	var snapshot database.MonitorSnapshot
+	if err := database.GetDB().Where("site_id = ?", site.ID).First(&snapshot).Error; err != nil {
+		t.Fatal(err)
+	}
+	if eventCount != 0 || snapshot.PriceMinor != 10000 {
+		t.Fatalf("replay must not persist events or snapshots: events=%d price=%d", eventCount, snapshot.PriceMinor)
+	}
+
+	proposed := *site
+	proposed.Fields = []database.SiteField{{Name: "title", Selector: "h1"}, {Name: "price", Selector: ".missing"}}
+	proposedResult, err := ReplayArchive(&proposed, oldest)
+	if err != nil {
+		t.Fatalf("replay proposed definition: %v", err)
+	}
+	if _, ok := proposedResult.Items[0]["price"]; ok || len(proposedResult.Events) != 0 {
+		t.Fatalf("proposed selector should extract no price, got %+v", proposedResult)
+	}
+
+	if _, _, err := engine.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("third check: %v", err)
+	}
+	archives, err = database.ListPageArchives(site.ID, 10)
+	if err != nil || len(archives) != 2 || archives[1].ID == oldest.ID {
+		t.Fatalf("retention must keep the two newest archives, got %+v err=%v", archives, err)
+	}
+}
@@ -801,6 +801,87 @@ func TestNormalizeAndValidateSiteDefinition(t *testing.T) {
 	}
 }
 
+func TestNormalizeAndValidateSiteDefinitionFieldTypes(t *testing.T) {
+	newSite := func(mode string, fields ...database.SiteField) *database.Site {
+		return &database.Site{
+			URL: "https://example.com/list", ExtractMode: mode, Container: ".list", StrategyType: "presence",
+			StrategyConfig: `{"type":"presence","identity":{"source":"source_url"},"on_first_baseline":"silent"}`,
+			Fields:         fields,
+		}
+	}
+	valid := []*database.Site{
+		newSite("", database.SiteField{Name: "tags", Selector: ".tag", Type: FieldTypeList},
+			database.SiteField{Name: "summary", Selector: ".desc", Type: FieldTypeHTML},
+			database.SiteField{Name: "stock", Selector: ".badge", Type: FieldTypeCount}),
+		newSite("json", database.SiteField{Name: "tags", Selector: "tags[*]", Type: FieldTypeList},
+			database.SiteField{Name: "stock", Selector: "stores[*]", Type: FieldTypeCount}),
+		newSite("json", database.SiteField{Name: "url", Selector: "link", Type: "text"},
+			database.SiteField{Name: "body", Selector: ".article", Type: FieldTypeHTML, Scope: FieldScopeDetail}),
+	}
+	for i, site := range valid {
+		if err := NormalizeAndValidateSiteDefinition(site); err != nil {
+			t.Errorf("valid site %d rejected: %v", i, err)
+		}
+	}
+	invalid := []*database.Site{
+		newSite("json", database.SiteField{Name: "summary", Selector: "desc", Type: FieldTypeHTML}),
+		newSite("feed", database.SiteField{Name: "title", Type: FieldTypeCount}),
+		newSite("", database.SiteField{Name: "tags", Selector: ".tag", Type: "lists"}),
+	}
+	for i, site := range invalid {
+		if err := NormalizeAndValidateSiteDefinition(site); err == nil {
+			t.Errorf("invalid site %d accepted", i)
+		}
+	}
+}
+
+func TestComputeFingerprintListFields(t *testing.T) {
+	extracted := ComputeFingerprint(map[string]interface{}{"title": "公告", "tags": []string{"招聘", "校园"}})
+	restored := ComputeFingerprint(map[string]interface{}{"tags": []interface{}{"校园", "招聘"}, "title": "公告"})
+	if extracted != restored {
+		t.Errorf("list fingerprint should ignore order and element type: %s != %s", extracted, restored)
+	}
+	changed := ComputeFingerprint(map[string]interface{}{"title": "公告", "tags": []string{"招聘"}})
+	if extracted == changed {
+		t.Error("removing a list value should change the fingerprint")
+	}
+}
+
+func TestListFieldOrderDoesNotChangeSnapshot(t *testing.T) {
+	site := &database.Site{
+		Name: "tags", URL: "https://example.com/list", Container: "ul", Item: "li",
+		StrategyType:   "field_changed",
+		StrategyConfig: `{"type":"field_changed","identity":{"field":"url"},"watch":{"fields":["tags"]}}`,
+		Fields: []database.SiteField{
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+			{Name: "tags", Selector: ".tag", Type: "list"},
+		},
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	detector := engine.detector
+	baseline := detector.Evaluate(SnapshotSet{}, engine.toObservations([]ExtractResult{{"url": "/a/1", "tags": []string{"x", "y"}}}))
+	previous := make(SnapshotSet)
+	for _, snapshot := range baseline.NextSnapshots {
+		previous[snapshot.ItemKey] = snapshot
+	}
+
+	result := detector.Evaluate(previous, engine.toObservations([]ExtractResult{{"url": "/a/1", "tags": []string{"y", "x"}}}))
+	if len(result.Events) != 0 {
+		t.Fatalf("reordering list values should not produce events, got %+v", result.Events)
+	}
+	if result.NextSnapshots[0].Fingerprint != baseline.NextSnapshots[0].Fingerprint {
+		t.Error("reordering list values should not change the snapshot fingerprint")
+	}
+
+	result = detector.Evaluate(previous, engine.toObservations([]ExtractResult{{"url": "/a/1", "tags": []string{"y", "z"}}}))
+	if len(result.Events) != 1 || result.Events[0].OldValue != "x, y" || result.Events[0].NewValue != "y, z" {
+		t.Fatalf("changed list values should produce one event, got %+v", result.Events)
+	}
+}
+
 func TestPriceEventDoesNotUseSKUAsURL(t *testing.T) {
 	detector := NewFieldTransitionDetector(DetectionRule{
 		Type:       "field_transition",
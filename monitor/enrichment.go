@@ -0,0 +1,236 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/cn-maul/Gentry/database"
+	"github.com/cn-maul/Gentry/fetcher"
+)
+
+const (
+	// 字段作用范围
+	FieldScopeList   = "list"   // 从列表条目提取
+	FieldScopeDetail = "detail" // 从条目 url 指向的详情页提取
+
+	defaultEnrichmentConcurrency = 2
+	maxEnrichmentConcurrency     = 8
+)
+
+// EnrichmentConfig 详情页补充抓取配置。
+type EnrichmentConfig struct {
+	// Concurrency 同时抓取的详情页数量，默认 2，上限 8
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+func (c *EnrichmentConfig) normalize() error {
+	if c.Concurrency < 0 || c.Concurrency > maxEnrichmentConcurrency {
+		return fmt.Errorf("详情页并发数必须在 1-%d 之间", maxEnrichmentConcurrency)
+	}
+	return nil
+}
+
+func (c *FetchConfig) enrichmentConcurrency() int {
+	if c.Enrichment == nil || c.Enrichment.Concurrency == 0 {
+		return defaultEnrichmentConcurrency
+	}
+	return c.Enrichment.Concurrency
+}
+
+// detailRequest 构造详情页请求：沿用请求头、Cookie 和字符集，但始终使用 GET。
+func (c *FetchConfig) detailRequest(targetURL string) fetcher.Request {
+	detail := *c
+	detail.Method, detail.Body = "", ""
+	return detail.Request(targetURL)
+}
+
+// detailExtractor 拆出详情页字段；返回的提取器把整个详情页视为一个条目。
+func detailExtractor(fields []database.SiteField) *Extractor {
+	var detailFields []FieldConfig
+	for _, f := range fields {
+		if f.Scope == FieldScopeDetail {
+			detailFields = append(detailFields, FieldConfig{
+				Name: f.Name, Selector: f.Selector, Type: f.Type, Attr: f.Attr, Transform: f.Transform,
+			})
+		}
+	}
+	if len(detailFields) == 0 {
+		return nil
+	}
+	return NewExtractor(SiteSelectors{Mode: ExtractModeHTML, Container: "html", Fields: detailFields})
+}
+
+// listSelectors 构造列表提取器配置，详情页字段不参与列表提取。
+func listSelectors(site *database.Site) SiteSelectors {
+	selectors := SiteSelectors{
+		Mode:      site.ExtractMode,
+		Container: site.Container,
+		Item:      site.Item,
+	}
+	for _, f := range site.Fields {
+		if f.Scope == FieldScopeDetail {
+			continue
+		}
+		selectors.Fields = append(selectors.Fields, FieldConfig{
+			Name:      f.Name,
+			Selector:  f.Selector,
+			Type:      f.Type,
+			Attr:      f.Attr,
+			Transform: f.Transform,
+		})
+	}
+	return selectors
+}
+
+// detailFieldNames 返回详情页字段名，顺序与配置一致。
+func detailFieldNames(fields []database.SiteField) []string {
+	var names []string
+	for _, f := range fields {
+		if f.Scope == FieldScopeDetail {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// fetchDetails 并发抓取条目详情页并把提取结果合并进条目。
+// 单个详情页失败只记录日志，条目保留列表字段继续参与检测。
+func fetchDetails(ctx context.Context, source fetcher.Source, config *FetchConfig, extractor *Extractor, siteName string, items []ExtractResult) {
+	if extractor == nil || len(items) == 0 {
+		return
+	}
+	details := make([]ExtractResult, len(items))
+	sem := make(chan struct{}, config.enrichmentConcurrency())
+	var wg sync.WaitGroup
+	for i, item := range items {
+		detailURL := toString(item["url"])
+		if detailURL == "" {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(i int, detailURL string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				detail, err := fetchDetail(ctx, source, config, extractor, detailURL)
+				if err != nil {
+					log.Printf("[%s] 抓取详情页失败 %s: %v", siteName, detailURL, err)
+					return
+				}
+				details[i] = detail
+			}(i, detailURL)
+		}
+	}
+	wg.Wait()
+	for i, detail := range details {
+		for name, value := range detail {
+			items[i][name] = value
+		}
+	}
+}
+
+func fetchDetail(ctx context.Context, source fetcher.Source, config *FetchConfig, extractor *Extractor, detailURL string) (ExtractResult, error) {
+	resp, err := source.Do(ctx, config.detailRequest(detailURL))
+	if err != nil {
+		return nil, err
+	}
+	if err := config.detectChallenge(detailURL, resp); err != nil {
+		return nil, err
+	}
+	results, err := extractor.Extract(string(resp.Body))
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("未提取到详情字段")
+	}
+	return results[0], nil
+}
+
+// enrich 只为新增或列表字段发生变化的条目抓取详情页，其余条目沿用上次快照中的详情字段，
+// 返回本次抓取或沿用了详情字段的条目，供统计详情页字段命中率。
+// 检测条件或监控字段来自详情页时同样只在上述条目上更新，避免每次检查都访问全部详情页。
+func (e *Engine) enrich(ctx context.Context, observations []Observation, previous SnapshotSet) []ExtractResult {
+	if e.detailExtractor == nil {
+		return nil
+	}
+	detailNames := detailFieldNames(e.site.Fields)
+	dataTypes := e.parseFieldDataTypes()
+	var (
+		pending     []int
+		items       []ExtractResult
+		detailItems []ExtractResult
+	)
+	for i, obs := range observations {
+		if snapshot, ok := previous[obs.ItemKey]; ok && listFieldsUnchanged(obs, snapshot.Payload, detailNames) {
+			reused := false
+			for _, name := range detailNames {
+				// 沿用快照中的字段值，list 字段在快照中已是排序后拼接的字符串
+				value, ok := snapshot.Payload[name]
+				if !ok {
+					continue
+				}
+				obs.Raw[name] = value
+				obs.Fields[name] = NormalizeFieldIn(fieldValueString(value), fieldDataType(dataTypes, name), e.location)
+				reused = true
+			}
+			if reused {
+				detailItems = append(detailItems, obs.Raw)
+			}
+			continue
+		}
+		pending = append(pending, i)
+		items = append(items, ExtractResult{"url": obs.Raw["url"]})
+	}
+	fetchDetails(ctx, e.source, e.fetchConfig, e.detailExtractor, e.site.Name, items)
+	for n, i := range pending {
+		obs := observations[i]
+		for _, name := range detailNames {
+			value, ok := items[n][name]
+			if !ok {
+				continue
+			}
+			obs.Raw[name] = value
+			obs.Fields[name] = NormalizeFieldIn(fieldValueString(value), fieldDataType(dataTypes, name), e.location)
+		}
+		detailItems = append(detailItems, obs.Raw)
+	}
+	return detailItems
+}
+
+// listFieldsUnchanged 判断条目的列表字段是否与快照一致，一致时沿用快照中的详情字段。
+func listFieldsUnchanged(obs Observation, payload map[string]interface{}, detailNames []string) bool {
+	detail := make(map[string]struct{}, len(detailNames))
+	for _, name := range detailNames {
+		detail[name] = struct{}{}
+	}
+	for name, value := range obs.Fields {
+		if _, isDetail := detail[name]; isDetail {
+			continue
+		}
+		if previous, ok := payload[name]; !ok || toString(previous) != value.Value {
+			return false
+		}
+	}
+	for name := range payload {
+		if _, isDetail := detail[name]; isDetail || strings.HasPrefix(name, "_") {
+			continue
+		}
+		if _, ok := obs.Fields[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldDataType(dataTypes map[string]string, name string) string {
+	if dataType, ok := dataTypes[name]; ok {
+		return dataType
+	}
+	return "text"
+}
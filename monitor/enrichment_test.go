@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func TestCheckOnceEnrichesOnlyNewOrChangedItems(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var (
+		mu       sync.Mutex
+		titles   = map[string]string{"1": "公告 1", "2": "公告 2", "3": "公告 3", "4": "公告 4"}
+		hits     = make(map[string]int)
+		inFlight int32
+		peak     int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := strings.CutPrefix(r.URL.Path, "/detail/"); ok {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				seen := atomic.LoadInt32(&peak)
+				if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+					break
+				}
+			}
+			mu.Lock()
+			hits[id]++
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			fmt.Fprintf(w, `<html><body><div class="content">正文 %s</div></body></html>`, id)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		var list strings.Builder
+		for _, id := range []string{"1", "2", "3", "4", "5"} {
+			if title, ok := titles[id]; ok {
+				fmt.Fprintf(&list, `<li><a href="/detail/%s">%s</a></li>`, id, title)
+			}
+		}
+		fmt.Fprintf(w, `<html><body><ul>%s</ul></body></html>`, list.String())
+	}))
+	defer server.Close()
+
+	site := &database.Site{
+		Name: "detail-enrichment", URL: server.URL + "/list", Container: "ul", Item: "li", StrategyType: "presence",
+		StrategyConfig: `{"type":"presence","identity":{"source":"source_url"},"on_first_baseline":"silent"}`,
+		FetchConfig:    `{"enrichment":{"concurrency":1}}`,
+		ConfigVersion:  1,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+			{Name: "content", Selector: ".content", Type: "text", Scope: FieldScopeDetail},
+		},
+	}
+	if err := database.CreateSiteWithFields(site); err != nil {
+		t.Fatalf("create site: %v", err)
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	report, err := engine.ValidateExtraction(context.Background())
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	mu.Lock()
+	if len(hits) != 3 || report.DetailSkipped != 1 {
+		t.Fatalf("validation should only enrich a small sample, got hits=%v skipped=%d", hits, report.DetailSkipped)
+	}
+	for id := range hits {
+		delete(hits, id)
+	}
+	mu.Unlock()
+
+	if _, isFirstBaseline, err := engine.CheckOnce(context.Background()); err != nil || !isFirstBaseline {
+		t.Fatalf("first check: baseline=%v err=%v", isFirstBaseline, err)
+	}
+	mu.Lock()
+	if len(hits) != 0 {
+		t.Fatalf("silent baseline should not fetch detail pages, got %v", hits)
+	}
+	titles["2"] = "公告 2（更正）"
+	titles["5"] = "公告 5"
+	mu.Unlock()
+
+	events, _, err := engine.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("second check: %v", err)
+	}
+	mu.Lock()
+	if len(hits) != 2 || hits["2"] != 1 || hits["5"] != 1 {
+		t.Fatalf("only new or changed items should be enriched, got %v", hits)
+	}
+	mu.Unlock()
+	if got := atomic.LoadInt32(&peak); got > 1 {
+		t.Fatalf("detail fetches exceeded concurrency cap: %d", got)
+	}
+	if len(events) != 1 || events[0].After["content"] != "正文 5" {
+		t.Fatalf("expected enriched item_added event, got %+v", events)
+	}
+
+	if events, _, err := engine.CheckOnce(context.Background()); err != nil || len(events) != 0 {
+		t.Fatalf("third check: events=%+v err=%v", events, err)
+	}
+	mu.Lock()
+	if len(hits) != 2 {
+		t.Fatalf("unchanged items must not be fetched again, got %v", hits)
+	}
+	mu.Unlock()
+
+	payloads := make(map[string]map[string]interface{})
+	for _, id := range []string{"1", "2"} {
+		var snapshot database.MonitorSnapshot
+		if err := database.GetDB().Where("site_id = ? AND item_key = ?", site.ID, server.URL+"/detail/"+id).First(&snapshot).Error; err != nil {
+			t.Fatal(err)
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(snapshot.PayloadJSON), &payload); err != nil {
+			t.Fatal(err)
+		}
+		payloads[id] = payload
+	}
+	if payloads["2"]["content"] != "正文 2" {
+		t.Fatalf("changed item must keep its detail fields across checks, got %v", payloads["2"])
+	}
+	if _, ok := payloads["1"]["content"]; ok {
+		t.Fatalf("baseline items without changes should not be enriched, got %v", payloads["1"])
+	}
+}
+
+func TestNormalizeValidatesDetailFields(t *testing.T) {
+	base := func() *database.Site {
+		return &database.Site{
+			URL: "https://example.com/list", Container: "ul", Item: "li",
+			Fields: []database.SiteField{
+				{Name: "title", Selector: "a"},
+				{Name: "url", Selector: "a", Type: "attr"},
+				{Name: "sku", Selector: ".sku", Scope: FieldScopeDetail},
+			},
+		}
+	}
+	site := base()
+	if err := NormalizeAndValidateSiteDefinition(site); err != nil {
+		t.Fatalf("valid detail fields rejected: %v", err)
+	}
+	if site.Fields[0].Scope != FieldScopeList || site.Fields[2].Scope != FieldScopeDetail {
+		t.Fatalf("unexpected normalized scopes: %+v", site.Fields)
+	}
+
+	site = base()
+	site.Fields = append(site.Fields[:1], site.Fields[2])
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil || !strings.Contains(err.Error(), "url") {
+		t.Fatalf("detail fields without list url must be rejected, got %v", err)
+	}
+
+	site = base()
+	site.StrategyConfig = `{"type":"presence","identity":{"field":"sku"}}`
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil || !strings.Contains(err.Error(), "详情页字段") {
+		t.Fatalf("identity on detail field must be rejected, got %v", err)
+	}
+
+	site = base()
+	site.Fields[2].Scope = "page"
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil {
+		t.Fatal("unknown scope must be rejected")
+	}
+}
+
+func TestDetailFieldsFeedNotificationsAndKeywords(t *testing.T) {
+	event := ChangeEvent{
+		EventType: "item_added", Title: "公告", URL: "https://example.com/a",
+		Details: eventDetails([]string{"content", "missing"}, `{"title":"公告","content":"面试名单"}`),
+	}
+	_, content := FormatEvent(event, "站点")
+	if !strings.HasSuffix(content, "\ncontent: 面试名单") {
+		t.Fatalf("detail fields missing from notification: %q", content)
+	}
+	if !matchEventKeywords(event, "面试") {
+		t.Fatal("keywords should match detail fields")
+	}
+
+	item := ExtractResult{"title": "公告", "url": "https://example.com/a", "content": "面试名单"}
+	if matchKeywords(item, []string{"面试"}) {
+		t.Fatal("detail fields should only be matched when named")
+	}
+	if !matchKeywords(item, []string{"面试"}, "content") {
+		t.Fatal("legacy keyword filter should match detail fields")
+	}
+	if _, body := buildNotifyContent("站点", []ExtractResult{item}, "content"); !strings.Contains(body, "content: 面试名单") {
+		t.Fatalf("legacy notification missing detail fields: %q", body)
+	}
+}
@@ -1,7 +1,9 @@
 package monitor
 
 import (
-	"regexp"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -10,8 +12,39 @@ import (
 // ExtractResult 表示从网页中提取的单个结果项
 type ExtractResult map[string]interface{}
 
+// 提取模式
+const (
+	ExtractModeHTML = "html" // CSS 选择器，xpath: 前缀为 XPath 表达式
+	ExtractModeJSON = "json" // JSONPath 表达式
+)
+
+// 多值和 HTML 字段类型；text、attr 以及结构化数据类型见各自的提取逻辑
+const (
+	// FieldTypeList 所有匹配节点的文本（Attr 非空时为该属性）组成的列表，转换逐项执行
+	FieldTypeList = "list"
+	// FieldTypeHTML 第一个匹配节点的内部 HTML
+	FieldTypeHTML = "html"
+	// FieldTypeCount 匹配节点的数量，没有匹配时为 0
+	FieldTypeCount = "count"
+)
+
+// isSupportedFieldType 报告提取模式是否支持该字段类型；html 和结构化数据类型只能用于 HTML 页面。
+func isSupportedFieldType(mode, fieldType string) bool {
+	switch fieldType {
+	case "text":
+		return true
+	case "attr", FieldTypeList, FieldTypeCount:
+		return mode != ExtractModeFeed
+	case FieldTypeHTML:
+		return mode == ExtractModeHTML
+	}
+	return mode == ExtractModeHTML && isStructuredFieldType(fieldType)
+}
+
 // SiteSelectors 提取器选择器配置
 type SiteSelectors struct {
+	// Mode 提取模式，为空时按 HTML 处理
+	Mode      string
 	Container string
 	Item      string
 	Fields    []FieldConfig
@@ -27,6 +60,7 @@ type FieldConfig struct {
 }
 
 type Extractor struct {
+	mode              string
 	containerSelector string
 	itemSelector      string
 	fields            []FieldConfig
@@ -34,28 +68,69 @@ type Extractor struct {
 
 func NewExtractor(selectors SiteSelectors) *Extractor {
 	return &Extractor{
+		mode:              selectors.Mode,
 		containerSelector: selectors.Container,
 		itemSelector:      selectors.Item,
 		fields:            selectors.Fields,
 	}
 }
 
+// Extract 按提取模式解析页面内容，返回的结果结构与模式无关。
 func (e *Extractor) Extract(html string) ([]ExtractResult, error) {
+	// 转换规则在保存时已严格校验，这里兼容升级前保存的旧规则
+	transforms := make([]transformPipeline, len(e.fields))
+	for i, field := range e.fields {
+		transforms[i] = compileStoredTransform(field.Transform)
+	}
+	switch e.mode {
+	case ExtractModeJSON:
+		return e.extractJSON(html, transforms)
+	case ExtractModeFeed:
+		return e.extractFeed(html, transforms)
+	}
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return nil, err
 	}
 
+	container, err := compileHTMLSelector(e.containerSelector)
+	if err != nil {
+		return nil, err
+	}
+	item, err := compileHTMLSelector(e.itemSelector)
+	if err != nil {
+		return nil, err
+	}
+	fieldSelectors := make([]htmlSelector, len(e.fields))
+	fieldPaths := make([]jsonPath, len(e.fields))
+	for i, field := range e.fields {
+		if isStructuredFieldType(field.Type) {
+			fieldPaths[i], err = compileJSONPath(field.Selector)
+		} else {
+			fieldSelectors[i], err = compileHTMLSelector(field.Selector)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("字段 %s: %w", field.Name, err)
+		}
+	}
+	structured := newStructuredData(doc.Selection)
+
 	var results []ExtractResult
 
-	items := doc.Find(e.containerSelector)
-	if strings.TrimSpace(e.itemSelector) != "" {
-		items = items.Find(e.itemSelector)
+	items := container.find(doc.Selection)
+	if !item.empty() {
+		items = item.find(items)
 	}
 	items.Each(func(_ int, s *goquery.Selection) {
 		result := make(ExtractResult)
-		for _, field := range e.fields {
-			if value := e.extractField(s, field); value != nil {
+		for i, field := range e.fields {
+			if isStructuredFieldType(field.Type) {
+				if value, ok := structured.value(s, fieldPaths[i], field); ok {
+					result[field.Name] = transforms[i].apply(value)
+				}
+				continue
+			}
+			if value := e.extractField(s, fieldSelectors[i], transforms[i], field); value != nil {
 				result[field.Name] = value
 			}
 		}
@@ -67,10 +142,20 @@ func (e *Extractor) Extract(html string) ([]ExtractResult, error) {
 	return results, nil
 }
 
-func (e *Extractor) extractField(s *goquery.Selection, field FieldConfig) interface{} {
+func (e *Extractor) extractField(s *goquery.Selection, selector htmlSelector, transform transformPipeline, field FieldConfig) interface{} {
+	// 返回字符串的 XPath 表达式（如 normalize-space(...)、count(...)）直接作为字段值
+	if value, ok := selector.scalar(s); ok {
+		if field.Type == FieldTypeList {
+			return listValue([]string{transform.apply(value)})
+		}
+		return transform.apply(value)
+	}
 	sel := s
-	if field.Selector != "" {
-		sel = s.Find(field.Selector)
+	if !selector.empty() {
+		sel = selector.find(s)
+	}
+	if field.Type == FieldTypeCount {
+		return transform.apply(strconv.Itoa(sel.Length()))
 	}
 	if sel.Length() == 0 {
 		if field.Type == "text" && field.Name == "title" {
@@ -94,62 +179,166 @@ func (e *Extractor) extractField(s *goquery.Selection, field FieldConfig) interf
 		value, _ = sel.Attr(attr)
 	case "text":
 		value = strings.TrimSpace(sel.Text())
+	case FieldTypeHTML:
+		html, err := sel.First().Html()
+		if err != nil {
+			return nil
+		}
+		value = strings.TrimSpace(html)
+	case FieldTypeList:
+		var values []string
+		sel.Each(func(_ int, node *goquery.Selection) {
+			var text string
+			if field.Attr != "" {
+				text, _ = node.Attr(field.Attr)
+			} else {
+				text = strings.TrimSpace(node.Text())
+			}
+			values = append(values, transform.apply(text))
+		})
+		return listValue(values)
 	default:
 		return nil
 	}
 
-	if field.Transform != "" {
-		value = applyTransform(value, field.Transform)
+	return transform.apply(value)
+}
+
+// listValue 去掉空项，全部为空时返回 nil 使字段缺失，与单值字段一致。
+func listValue(values []string) interface{} {
+	var result []string
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			result = append(result, value)
+		}
 	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
 
-	return value
+// extractJSON 使用 JSONPath 提取：Container 相对文档根节点求值，
+// Item 与字段选择器相对当前节点求值。Item 为空时容器匹配到的节点即条目。
+func (e *Extractor) extractJSON(body string, transforms []transformPipeline) ([]ExtractResult, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+	}
+
+	containerPath, err := compileJSONPath(e.containerSelector)
+	if err != nil {
+		return nil, err
+	}
+	items := containerPath.eval(root)
+	if strings.TrimSpace(e.itemSelector) != "" {
+		itemPath, err := compileJSONPath(e.itemSelector)
+		if err != nil {
+			return nil, err
+		}
+		var nested []interface{}
+		for _, container := range items {
+			nested = append(nested, itemPath.eval(container)...)
+		}
+		items = nested
+	}
+
+	fieldPaths := make([]jsonPath, len(e.fields))
+	for i, field := range e.fields {
+		if fieldPaths[i], err = compileJSONPath(field.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []ExtractResult
+	for _, item := range items {
+		result := make(ExtractResult)
+		for i, field := range e.fields {
+			if value, ok := extractJSONField(item, fieldPaths[i], transforms[i], field); ok {
+				result[field.Name] = value
+			}
+		}
+		if len(result) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// extractFeed 解析订阅源，字段选择器为标准字段名（title、url、date、summary、guid），为空时使用字段名。
+func (e *Extractor) extractFeed(body string, transforms []transformPipeline) ([]ExtractResult, error) {
+	entries, err := parseFeed(body)
+	if err != nil {
+		return nil, err
+	}
+	var results []ExtractResult
+	for _, entry := range entries {
+		result := make(ExtractResult)
+		for i, field := range e.fields {
+			source := field.Selector
+			if source == "" {
+				source = field.Name
+			}
+			value, ok := entry[source].(string)
+			if !ok {
+				continue
+			}
+			result[field.Name] = transforms[i].apply(value)
+		}
+		if len(result) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results, nil
 }
 
-// applyTransform 应用转换规则
-// 支持格式:
-//
-//	trim(chars)    — 去除两端指定字符
-//	prefix(text)   — 添加前缀
-//	suffix(text)   — 添加后缀
-//	regexp(pat,repl) — 正则替换
-func applyTransform(value, transform string) string {
-	if value == "" || transform == "" {
-		return value
-	}
-
-	// 解析 transform: funcName(args)
-	idx := strings.Index(transform, "(")
-	if idx < 0 || !strings.HasSuffix(transform, ")") {
-		return value
-	}
-
-	name := transform[:idx]
-	args := transform[idx+1 : len(transform)-1]
-
-	switch name {
-	case "trim":
-		return strings.Trim(value, args)
-	case "prefix":
-		return args + value
-	case "suffix":
-		return value + args
-	case "regexp":
-		parts := strings.SplitN(args, ",", 2)
-		if len(parts) == 2 {
-			pattern := strings.TrimSpace(parts[0])
-			replacement := strings.TrimSpace(parts[1])
-			// 去掉可能的引号
-			pattern = strings.Trim(pattern, `"'`)
-			replacement = strings.Trim(replacement, `"'`)
-			re, err := regexp.Compile(pattern)
-			if err != nil {
-				// 编译失败则返回原值
-				return value
+// extractJSONField 取选择器匹配到的第一个节点；attr 类型读取该节点的 Attr 成员，
+// list 类型读取所有匹配节点，count 类型返回匹配节点数。
+func extractJSONField(item interface{}, path jsonPath, transform transformPipeline, field FieldConfig) (interface{}, bool) {
+	matches := path.eval(item)
+	switch field.Type {
+	case FieldTypeCount:
+		return transform.apply(strconv.Itoa(len(matches))), true
+	case FieldTypeList:
+		values := make([]string, 0, len(matches))
+		for _, node := range matches {
+			if value, ok := jsonMemberText(node, field.Attr); ok {
+				values = append(values, transform.apply(value))
 			}
-			return re.ReplaceAllString(value, replacement)
 		}
-		return value
+		value := listValue(values)
+		return value, value != nil
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+	var member string
+	switch field.Type {
+	case "attr":
+		member = field.Attr
+	case "text":
 	default:
-		return value
+		return "", false
+	}
+	value, ok := jsonMemberText(matches[0], member)
+	if !ok {
+		return "", false
+	}
+	return transform.apply(value), true
+}
+
+// jsonMemberText 读取节点的标量文本；member 非空时读取对象的该成员。
+func jsonMemberText(node interface{}, member string) (string, bool) {
+	if member != "" {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if node, ok = object[member]; !ok {
+			return "", false
+		}
 	}
+	return jsonScalarText(node)
 }
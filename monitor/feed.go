@@ -0,0 +1,197 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+// ExtractModeFeed RSS 2.0 / Atom 订阅源
+const ExtractModeFeed = "feed"
+
+// feedStandardFields 订阅源模式下可用的标准字段，顺序即默认字段顺序
+var feedStandardFields = []string{"title", "url", "date", "summary", "guid"}
+
+type rssDocument struct {
+	XMLName xml.Name  `xml:"rss"`
+	Items   []rssItem `xml:"channel>item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	PubDate     string  `xml:"pubDate"`
+	Date        string  `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+type rssGUID struct {
+	Value string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	ID        string     `xml:"id"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// parseFeed 将 RSS 2.0 或 Atom 文档解析为标准字段条目。
+// guid 缺失时依次回退到链接和标题，保证条目身份稳定。
+func parseFeed(body string) ([]ExtractResult, error) {
+	root, err := feedRootName(body)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ExtractResult
+	switch {
+	case root.Local == "rss":
+		var doc rssDocument
+		if err := decodeFeedXML(body, &doc); err != nil {
+			return nil, err
+		}
+		for _, item := range doc.Items {
+			date := item.PubDate
+			if strings.TrimSpace(date) == "" {
+				date = item.Date
+			}
+			entries = append(entries, newFeedEntry(item.Title, item.Link, date, item.Description, item.GUID.Value))
+		}
+	case root.Local == "feed" && root.Space == "http://www.w3.org/2005/Atom":
+		var doc atomFeed
+		if err := decodeFeedXML(body, &doc); err != nil {
+			return nil, err
+		}
+		for _, entry := range doc.Entries {
+			date := entry.Published
+			if strings.TrimSpace(date) == "" {
+				date = entry.Updated
+			}
+			summary := entry.Summary
+			if strings.TrimSpace(summary) == "" {
+				summary = entry.Content
+			}
+			entries = append(entries, newFeedEntry(entry.Title, atomEntryLink(entry.Links), date, summary, entry.ID))
+		}
+	default:
+		return nil, fmt.Errorf("不是 RSS 2.0 或 Atom 订阅源: <%s>", root.Local)
+	}
+	return entries, nil
+}
+
+func newFeedEntry(title, link, date, summary, guid string) ExtractResult {
+	entry := ExtractResult{}
+	values := map[string]string{
+		"title":   strings.TrimSpace(title),
+		"url":     strings.TrimSpace(link),
+		"date":    strings.TrimSpace(date),
+		"summary": feedPlainText(summary),
+		"guid":    strings.TrimSpace(guid),
+	}
+	if values["guid"] == "" {
+		values["guid"] = values["url"]
+	}
+	if values["guid"] == "" {
+		values["guid"] = values["title"]
+	}
+	for name, value := range values {
+		if value != "" {
+			entry[name] = value
+		}
+	}
+	return entry
+}
+
+// atomEntryLink 优先选择 rel=alternate（或未声明 rel）的链接。
+func atomEntryLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// feedPlainText 摘要常包含转义的 HTML，提取纯文本便于通知和关键词匹配。
+func feedPlainText(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "<") {
+		return value
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(value))
+	if err != nil {
+		return value
+	}
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+func feedRootName(body string) (xml.Name, error) {
+	decoder := newFeedDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return xml.Name{}, fmt.Errorf("订阅源内容为空")
+			}
+			return xml.Name{}, fmt.Errorf("解析订阅源失败: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func decodeFeedXML(body string, v interface{}) error {
+	if err := newFeedDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("解析订阅源失败: %w", err)
+	}
+	return nil
+}
+
+// newFeedDecoder 抓取器已按响应头转码时内容是合法 UTF-8，此时忽略 XML 声明中的编码；
+// 否则按声明的编码转码。
+func newFeedDecoder(body string) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(body)))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		if utf8.ValidString(body) {
+			return input, nil
+		}
+		return charset.NewReaderLabel(label, input)
+	}
+	return decoder
+}
+
+// isFeedField 判断字段选择器是否为订阅源标准字段。
+func isFeedField(name string) bool {
+	for _, field := range feedStandardFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+const testRSSFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>公告</title>
+<item><title>第一条公告</title><link>https://example.com/a</link><pubDate>Tue, 07 Jul 2026 08:00:00 +0800</pubDate><description>&lt;p&gt;摘要 &amp;amp; 说明&lt;/p&gt;</description><guid isPermaLink="false">notice-1</guid></item>
+<item><title>第二条公告</title><link>/b</link></item>
+</channel></rss>`
+
+const testAtomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>更新日志</title>
+<entry><title>v1.2 发布</title><link rel="self" href="https://example.com/self"/><link rel="alternate" href="https://example.com/v1.2"/><id>tag:example.com,2026:v1.2</id><updated>2026-07-07T08:00:00Z</updated><summary>修复若干问题</summary></entry>
+</feed>`
+
+func TestParseFeedRSSAndAtom(t *testing.T) {
+	items, err := parseFeed(testRSSFeed)
+	if err != nil {
+		t.Fatalf("parse rss: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 rss items, got %+v", items)
+	}
+	first := items[0]
+	if first["title"] != "第一条公告" || first["guid"] != "notice-1" || first["summary"] != "摘要 & 说明" || first["date"] != "Tue, 07 Jul 2026 08:00:00 +0800" {
+		t.Fatalf("unexpected rss item: %+v", first)
+	}
+	if items[1]["guid"] != "/b" {
+		t.Fatalf("guid should fall back to link: %+v", items[1])
+	}
+
+	items, err = parseFeed(testAtomFeed)
+	if err != nil {
+		t.Fatalf("parse atom: %v", err)
+	}
+	if len(items) != 1 || items[0]["url"] != "https://example.com/v1.2" || items[0]["guid"] != "tag:example.com,2026:v1.2" || items[0]["date"] != "2026-07-07T08:00:00Z" {
+		t.Fatalf("unexpected atom item: %+v", items)
+	}
+
+	if _, err := parseFeed(`<html><body>not a feed</body></html>`); err == nil {
+		t.Fatal("expected html to be rejected as feed")
+	}
+}
+
+func TestFeedMonitorUsesGUIDIdentity(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var feed atomic.Value
+	feed.Store(testRSSFeed)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(feed.Load().(string)))
+	}))
+	defer server.Close()
+
+	site := &database.Site{
+		Name: "feed-monitor", URL: server.URL + "/feed.xml", ExtractMode: "feed", StrategyType: "presence",
+		StrategyConfig: `{"type":"presence","identity":{"source":"source_url"},"on_first_baseline":"silent"}`,
+		ConfigVersion:  1,
+	}
+	if err := NormalizeAndValidateSiteDefinition(site); err != nil {
+		t.Fatalf("normalize feed site: %v", err)
+	}
+	if len(site.Fields) != len(feedStandardFields) || site.Container != "" {
+		t.Fatalf("feed site should default to standard fields: %+v", site)
+	}
+	if err := database.CreateSiteWithFields(site); err != nil {
+		t.Fatal(err)
+	}
+	m := NewDetachedMonitor(site)
+	if _, err := m.CheckNow(context.Background()); err != nil {
+		t.Fatalf("baseline check: %v", err)
+	}
+
+	// 标题修改但 guid 不变，不应视为新增；新 guid 才产生更新
+	feed.Store(`<rss version="2.0"><channel>
+<item><title>第一条公告（更正）</title><link>https://example.com/a</link><guid>notice-1</guid></item>
+<item><title>第三条公告</title><link>https://example.com/c</link><guid>notice-3</guid></item>
+</channel></rss>`)
+	outcome, err := m.CheckNow(context.Background())
+	if err != nil {
+		t.Fatalf("second check: %v", err)
+	}
+	if len(outcome.Updates) != 1 || outcome.Updates[0]["guid"] != "notice-3" {
+		t.Fatalf("expected only the new guid to be reported, got %+v", outcome.Updates)
+	}
+}
+
+func TestSmartScanOffersAlternateFeeds(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			_, _ = w.Write([]byte(testAtomFeed))
+		case "/broken.xml":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			_, _ = w.Write([]byte(`<html><head>
+<link rel="alternate" type="application/atom+xml" title="更新日志" href="/feed.xml">
+<link rel="alternate" type="application/rss+xml" href="/broken.xml">
+</head><body><ul class="news"><li><a href="/a">公告 A</a></li><li><a href="/b">公告 B</a></li><li><a href="/c">公告 C</a></li></ul></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	result, err := SmartScan(&ScanSettings{URL: server.URL + "/news"})
+	if err != nil {
+		t.Fatalf("smart scan: %v", err)
+	}
+	if len(result.Containers) == 0 || result.Containers[0].Strategy != "feed" {
+		t.Fatalf("expected feed candidate first, got %+v", result.Containers)
+	}
+	candidate := result.Containers[0]
+	if candidate.Config.URL != server.URL+"/feed.xml" || candidate.Config.ExtractMode != ExtractModeFeed || candidate.ItemCount != 1 {
+		t.Fatalf("unexpected feed candidate: %+v", candidate)
+	}
+	for _, other := range result.Containers[1:] {
+		if other.Strategy == "feed" {
+			t.Fatalf("unreachable feed should be skipped: %+v", other)
+		}
+	}
+
+	direct, err := SmartScan(&ScanSettings{URL: server.URL + "/feed.xml"})
+	if err != nil {
+		t.Fatalf("smart scan feed url: %v", err)
+	}
+	if len(direct.Containers) != 1 || direct.Containers[0].Strategy != "feed" {
+		t.Fatalf("feed URL should produce a single feed candidate: %+v", direct.Containers)
+	}
+}
@@ -0,0 +1,335 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cn-maul/Gentry/fetcher"
+)
+
+var (
+	httpTokenRegex = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+	// 由传输层维护的请求头，不允许站点覆盖。
+	reservedFetchHeaders = map[string]struct{}{
+		"Host": {}, "Content-Length": {}, "Transfer-Encoding": {}, "Connection": {},
+	}
+)
+
+// localSourcesEnabled 是否允许 file/exec 来源。两者可读取本机文件或执行命令，
+// 默认关闭，需由部署者通过 ALLOW_LOCAL_SOURCES 显式开启。
+var localSourcesEnabled atomic.Bool
+
+// SetLocalSourcesEnabled 设置是否允许本地文件和外部命令来源。
+func SetLocalSourcesEnabled(enabled bool) {
+	localSourcesEnabled.Store(enabled)
+}
+
+// SourceConfig 页面来源适配器配置，为空时使用 HTTP 抓取。
+type SourceConfig struct {
+	// Type 来源类型: http, file, exec
+	Type string `json:"type"`
+	// Path file 来源的本地文件或目录绝对路径
+	Path string `json:"path,omitempty"`
+	// Command/Args exec 来源的命令和参数，标准输出作为页面内容
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	// Timeout exec 来源的超时时间（秒）
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// FetchConfig 站点抓取配置，对应 database.Site.FetchConfig 列。
+type FetchConfig struct {
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// Charset 强制指定页面字符集（如 gbk、big5），为空时自动识别
+	Charset string `json:"charset,omitempty"`
+	// Source 页面来源适配器，为空时使用 HTTP 抓取
+	Source *SourceConfig `json:"source,omitempty"`
+	// Pagination 多页列表抓取配置，为空时只抓取首页
+	Pagination *PaginationConfig `json:"pagination,omitempty"`
+	// Enrichment 详情页补充抓取配置，为空时使用默认并发数
+	Enrichment *EnrichmentConfig `json:"enrichment,omitempty"`
+	// Archive 页面存档配置，为空时不保存抓取到的页面
+	Archive *ArchiveConfig `json:"archive,omitempty"`
+	// Retry 抓取失败时的重试配置，为空时使用默认重试策略
+	Retry *RetryConfig `json:"retry,omitempty"`
+	// Proxy 站点代理地址（http、https、socks5），"direct" 表示不使用全局默认代理
+	Proxy string `json:"proxy,omitempty"`
+	// ProxyPool 引用全局设置中的命名代理池，与 Proxy 二选一
+	ProxyPool string `json:"proxy_pool,omitempty"`
+	// TLS 站点级 TLS 设置：额外 CA、客户端证书、最低版本和跳过校验
+	TLS *fetcher.TLSOptions `json:"tls,omitempty"`
+	// BlockedPatterns 自定义拦截页特征（正则），命中时本次检查标记为被拦截
+	BlockedPatterns []string `json:"blocked_patterns,omitempty"`
+	// Session 登录会话，检查前执行登录步骤并在各次检查间复用 Cookie
+	Session *SessionConfig `json:"session,omitempty"`
+
+	blockedPatterns []*regexp.Regexp
+}
+
+// ParseFetchConfig 解析并校验抓取配置，空配置等价于默认 GET 请求。
+func ParseFetchConfig(configJSON string) (*FetchConfig, error) {
+	config := &FetchConfig{}
+	if strings.TrimSpace(configJSON) != "" {
+		if err := json.Unmarshal([]byte(configJSON), config); err != nil {
+			return nil, fmt.Errorf("解析抓取配置失败: %w", err)
+		}
+	}
+	if err := config.normalize(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *FetchConfig) normalize() error {
+	c.Method = strings.ToUpper(strings.TrimSpace(c.Method))
+	switch c.Method {
+	case "", http.MethodGet:
+		c.Method = ""
+		if c.Body != "" {
+			return fmt.Errorf("GET 请求不能配置请求体")
+		}
+	case http.MethodPost:
+	default:
+		return fmt.Errorf("抓取方法仅支持 GET 或 POST: %s", c.Method)
+	}
+
+	if len(c.Headers) > 0 {
+		headers := make(map[string]string, len(c.Headers))
+		for name, value := range c.Headers {
+			name = strings.TrimSpace(name)
+			if !httpTokenRegex.MatchString(name) {
+				return fmt.Errorf("请求头名称无效: %q", name)
+			}
+			canonical := http.CanonicalHeaderKey(name)
+			if _, reserved := reservedFetchHeaders[canonical]; reserved {
+				return fmt.Errorf("请求头 %s 由抓取器维护，不能自定义", canonical)
+			}
+			if _, exists := headers[canonical]; exists {
+				return fmt.Errorf("请求头重复: %s", canonical)
+			}
+			if strings.ContainsAny(value, "\r\n\x00") {
+				return fmt.Errorf("请求头 %s 的值包含非法字符", canonical)
+			}
+			headers[canonical] = strings.TrimSpace(value)
+		}
+		c.Headers = headers
+	}
+
+	if charsetName := strings.TrimSpace(c.Charset); charsetName != "" {
+		canonical, err := fetcher.LookupCharset(charsetName)
+		if err != nil {
+			return err
+		}
+		c.Charset = canonical
+	} else {
+		c.Charset = ""
+	}
+
+	if c.Source != nil {
+		if err := c.Source.normalize(); err != nil {
+			return err
+		}
+		if c.Source.Type == "http" {
+			c.Source = nil
+		}
+	}
+
+	if c.Pagination != nil {
+		if err := c.Pagination.normalize(); err != nil {
+			return err
+		}
+	}
+
+	if c.Enrichment != nil {
+		if err := c.Enrichment.normalize(); err != nil {
+			return err
+		}
+		if *c.Enrichment == (EnrichmentConfig{}) {
+			c.Enrichment = nil
+		}
+	}
+
+	if c.Archive != nil {
+		if err := c.Archive.normalize(); err != nil {
+			return err
+		}
+	}
+
+	if c.Retry != nil {
+		if err := c.Retry.normalize(); err != nil {
+			return err
+		}
+		if *c.Retry == (RetryConfig{}) {
+			c.Retry = nil
+		}
+	}
+
+	c.Proxy, c.ProxyPool = strings.TrimSpace(c.Proxy), strings.TrimSpace(c.ProxyPool)
+	if c.Proxy != "" && c.ProxyPool != "" {
+		return fmt.Errorf("代理和代理池只能配置一个")
+	}
+	if c.Proxy != "" && c.Proxy != fetcher.ProxyDirect {
+		if _, err := fetcher.ParseProxyURL(c.Proxy); err != nil {
+			return err
+		}
+	}
+	if c.Source != nil && (c.Proxy != "" || c.ProxyPool != "") {
+		return fmt.Errorf("%s 来源不支持代理", c.Source.Type)
+	}
+
+	if c.TLS != nil {
+		c.TLS.MinVersion = strings.TrimSpace(c.TLS.MinVersion)
+		if *c.TLS == (fetcher.TLSOptions{}) {
+			c.TLS = nil
+		} else if c.Source != nil {
+			return fmt.Errorf("%s 来源不支持 TLS 设置", c.Source.Type)
+		} else if _, err := c.TLS.Config(); err != nil {
+			return err
+		}
+	}
+
+	if c.Session != nil {
+		if c.Source != nil {
+			return fmt.Errorf("%s 来源不支持登录会话", c.Source.Type)
+		}
+		if err := c.Session.normalize(); err != nil {
+			return fmt.Errorf("登录会话配置无效: %w", err)
+		}
+	}
+
+	patterns := c.BlockedPatterns[:0]
+	for _, pattern := range c.BlockedPatterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		patterns = nil
+	}
+	compiled, err := compileBlockedPatterns(patterns)
+	if err != nil {
+		return err
+	}
+	c.BlockedPatterns, c.blockedPatterns = patterns, compiled
+
+	for name, value := range c.Cookies {
+		if !httpTokenRegex.MatchString(name) {
+			return fmt.Errorf("Cookie 名称无效: %q", name)
+		}
+		if strings.ContainsAny(value, ";\r\n\x00") {
+			return fmt.Errorf("Cookie %s 的值包含非法字符", name)
+		}
+	}
+	return nil
+}
+
+func (s *SourceConfig) normalize() error {
+	s.Type = strings.ToLower(strings.TrimSpace(s.Type))
+	s.Path = strings.TrimSpace(s.Path)
+	s.Command = strings.TrimSpace(s.Command)
+	switch s.Type {
+	case "", "http":
+		s.Type = "http"
+		if s.Path != "" || s.Command != "" || len(s.Args) > 0 || s.Timeout != 0 {
+			return fmt.Errorf("http 来源不支持 path、command、args 或 timeout")
+		}
+		return nil
+	case "file":
+		if s.Path == "" || !filepath.IsAbs(s.Path) {
+			return fmt.Errorf("file 来源必须配置绝对路径")
+		}
+		if s.Command != "" || len(s.Args) > 0 || s.Timeout != 0 {
+			return fmt.Errorf("file 来源不支持 command、args 或 timeout")
+		}
+		s.Path = filepath.Clean(s.Path)
+	case "exec":
+		if s.Command == "" {
+			return fmt.Errorf("exec 来源必须配置 command")
+		}
+		if s.Path != "" {
+			return fmt.Errorf("exec 来源不支持 path")
+		}
+		if s.Timeout < 0 {
+			return fmt.Errorf("exec 来源超时时间不能为负数")
+		}
+	default:
+		return fmt.Errorf("不支持的来源类型: %s", s.Type)
+	}
+	if !localSourcesEnabled.Load() {
+		return fmt.Errorf("%s 来源未启用，请设置 ALLOW_LOCAL_SOURCES=true", s.Type)
+	}
+	return nil
+}
+
+// NewSource 返回站点使用的来源适配器，HTTP 来源复用传入的 Fetcher 并按重试配置包装。
+func (c *FetchConfig) NewSource(httpFetcher *fetcher.Fetcher) fetcher.Source {
+	if c.Source == nil {
+		attempts, backoff := c.retryPolicy()
+		if attempts <= 1 {
+			return httpFetcher
+		}
+		return &retrySource{source: httpFetcher, attempts: attempts, backoff: backoff}
+	}
+	switch c.Source.Type {
+	case "file":
+		return &fetcher.FileSource{Path: c.Source.Path}
+	case "exec":
+		return &fetcher.CommandSource{
+			Command: c.Source.Command,
+			Args:    append([]string(nil), c.Source.Args...),
+			Timeout: time.Duration(c.Source.Timeout) * time.Second,
+		}
+	}
+	return httpFetcher
+}
+
+// Canonical 返回规范化后的 JSON；默认配置返回空字符串以兼容旧记录。
+func (c *FetchConfig) Canonical() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("规范化抓取配置失败: %w", err)
+	}
+	if string(data) == "{}" {
+		return "", nil
+	}
+	return string(data), nil
+}
+
+// Request 根据配置构造指定 URL 的抓取请求。
+func (c *FetchConfig) Request(targetURL string) fetcher.Request {
+	req := fetcher.Request{
+		Method: c.Method, URL: targetURL, Body: c.Body, Charset: c.Charset,
+		Proxy: c.Proxy, ProxyPool: c.ProxyPool, TLS: c.TLS,
+	}
+	if len(c.Headers) > 0 || c.Body != "" {
+		req.Header = make(http.Header, len(c.Headers)+1)
+		for name, value := range c.Headers {
+			req.Header.Set(name, value)
+		}
+		if c.Body != "" && req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if len(c.Cookies) > 0 {
+		names := make([]string, 0, len(c.Cookies))
+		for name := range c.Cookies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			req.Cookies = append(req.Cookies, &http.Cookie{Name: name, Value: c.Cookies[name]})
+		}
+	}
+	return req
+}
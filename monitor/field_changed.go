@@ -0,0 +1,203 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WatchConfig field_changed 策略监控的字段和比较选项
+type WatchConfig struct {
+	Fields []string `json:"fields"`
+	// IgnoreWhitespace 比较时忽略所有空白字符
+	IgnoreWhitespace bool `json:"ignore_whitespace,omitempty"`
+	// IgnoreCase 比较时忽略大小写
+	IgnoreCase bool `json:"ignore_case,omitempty"`
+	// IgnoreNumberFormat 比较时忽略数字的千分位、前导零和小数末尾的零，如 1,200.50 与 1200.5 视为相同
+	IgnoreNumberFormat bool `json:"ignore_number_format,omitempty"`
+}
+
+// fieldNames 返回监控的字段，未配置时为空。
+func (w *WatchConfig) fieldNames() []string {
+	if w == nil {
+		return nil
+	}
+	return append([]string(nil), w.Fields...)
+}
+
+// validateWatchConfig 校验 field_changed 策略：至少监控一个字段，字段必须存在且不能是身份字段。
+func validateWatchConfig(rule DetectionRule, fieldNames map[string]struct{}) error {
+	if len(rule.Conditions) > 0 {
+		return fmt.Errorf("field_changed 不支持 conditions，请使用 watch.fields 配置监控字段")
+	}
+	if rule.Watch == nil || len(rule.Watch.Fields) == 0 {
+		return fmt.Errorf("field_changed 至少需要监控一个字段")
+	}
+	identity := make(map[string]bool)
+	identity[rule.Identity.Field] = true
+	for _, field := range rule.Identity.Fields {
+		identity[field] = true
+	}
+	seen := make(map[string]bool, len(rule.Watch.Fields))
+	for _, field := range rule.Watch.Fields {
+		if _, ok := fieldNames[field]; !ok {
+			return fmt.Errorf("监控字段不存在: %s", field)
+		}
+		if identity[field] {
+			return fmt.Errorf("身份字段不能作为监控字段: %s", field)
+		}
+		if seen[field] {
+			return fmt.Errorf("监控字段重复: %s", field)
+		}
+		seen[field] = true
+	}
+	return nil
+}
+
+// maxEventValueRunes 事件中新旧值的最大长度，与 monitor_events 的列宽一致
+const maxEventValueRunes = 500
+
+// numberPattern 匹配数字，只把三位一组的逗号视为千分位，"第3,4期" 中的逗号是分隔符而不是千分位。
+var numberPattern = regexp.MustCompile(`\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?`)
+
+// comparable 按比较选项规范化字段值，只用于判断是否变化，事件中仍保留原值。
+func (w *WatchConfig) comparable(value string) string {
+	if w.IgnoreNumberFormat {
+		value = numberPattern.ReplaceAllStringFunc(value, canonicalNumber)
+	}
+	if w.IgnoreWhitespace {
+		value = strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, value)
+	}
+	if w.IgnoreCase {
+		value = strings.ToLower(value)
+	}
+	return value
+}
+
+// canonicalNumber 去掉千分位、整数前导零和小数末尾的零。
+func canonicalNumber(number string) string {
+	number = strings.ReplaceAll(number, ",", "")
+	integer, fraction, _ := strings.Cut(number, ".")
+	integer = strings.TrimLeft(integer, "0")
+	if integer == "" {
+		integer = "0"
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
+
+// FieldChangedDetector 检测已有条目的指定字段是否变化，每个变化的字段产生一个 field_changed 事件
+type FieldChangedDetector struct {
+	rule DetectionRule
+}
+
+func NewFieldChangedDetector(rule DetectionRule) *FieldChangedDetector {
+	return &FieldChangedDetector{rule: rule}
+}
+
+func (d *FieldChangedDetector) Validate(schema ExtractionSchema, config json.RawMessage) error {
+	if d.rule.Type != "field_changed" {
+		return fmt.Errorf("FieldChangedDetector 不能处理策略 %s", d.rule.Type)
+	}
+	return validateDetectionRule(d.rule, schema, extractionFieldNames(schema), map[string]string{})
+}
+
+func (d *FieldChangedDetector) Evaluate(previous SnapshotSet, current []Observation) EvaluationResult {
+	now := time.Now()
+	seen := make(map[string]bool)
+	var nextSnapshots []Snapshot
+	var events []ChangeEvent
+
+	for _, obs := range current {
+		itemKey := obs.ItemKey
+		if itemKey == "" {
+			continue
+		}
+		seen[itemKey] = true
+
+		payload := make(map[string]interface{})
+		for k, v := range obs.Fields {
+			payload[k] = v.Value
+		}
+		payload["_item_key"] = itemKey
+
+		ns := Snapshot{
+			ItemKey:    itemKey,
+			Payload:    payload,
+			LastSeenAt: now,
+		}
+
+		existing, exists := previous[itemKey]
+		if !exists {
+			ns.FirstSeenAt = now
+			ns.Fingerprint = computeFingerprint(payload)
+			nextSnapshots = append(nextSnapshots, ns)
+			continue
+		}
+		ns.FirstSeenAt = existing.FirstSeenAt
+		ns.DefinitionVersion = existing.DefinitionVersion
+
+		var changes []ChangeEvent
+		for _, field := range d.rule.Watch.Fields {
+			oldValue := extractStr(existing.Payload, field)
+			newValue := extractStr(payload, field)
+			// 本次未提取到值时视为选择器偶发失配，沿用旧值，不产生事件
+			if strings.TrimSpace(newValue) == "" {
+				if oldValue != "" {
+					payload[field] = oldValue
+				}
+				continue
+			}
+			if strings.TrimSpace(oldValue) == "" || d.rule.Watch.comparable(oldValue) == d.rule.Watch.comparable(newValue) {
+				continue
+			}
+			changes = append(changes, ChangeEvent{
+				EventType: "field_changed",
+				ItemKey:   itemKey,
+				Field:     field,
+				OldValue:  truncateRunes(oldValue, maxEventValueRunes),
+				NewValue:  truncateRunes(newValue, maxEventValueRunes),
+			})
+		}
+		ns.Fingerprint = computeFingerprint(payload)
+
+		if len(changes) > 0 && !d.rule.tooOld(obs.Fields, now) {
+			title := extractStr(payload, "title")
+			if title == "" {
+				title = itemKey
+			}
+			published, _ := d.rule.publishedAt(obs.Fields)
+			for _, change := range changes {
+				change.Title = title
+				change.URL = extractStr(payload, "url")
+				change.Before = existing.Payload
+				change.After = payload
+				change.OccurredAt = now
+				change.PublishedAt = published
+				events = append(events, change)
+			}
+		}
+		nextSnapshots = append(nextSnapshots, ns)
+	}
+
+	for key, snap := range previous {
+		if !seen[key] {
+			snap.MissingChecks++
+			snap.LastSeenAt = now
+			nextSnapshots = append(nextSnapshots, snap)
+		}
+	}
+
+	return EvaluationResult{NextSnapshots: nextSnapshots, Events: events}
+}
@@ -0,0 +1,180 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func fieldObservation(key string, fields map[string]string) Observation {
+	typed := make(map[string]TypedValue, len(fields))
+	for name, value := range fields {
+		typed[name] = TypedValue{Value: value, DataType: "text", Valid: true}
+	}
+	return Observation{ItemKey: key, Fields: typed, SeenAt: time.Now()}
+}
+
+func TestFieldChangedDetectorReportsEachChangedField(t *testing.T) {
+	detector := NewFieldChangedDetector(DetectionRule{
+		Type:  "field_changed",
+		Watch: &WatchConfig{Fields: []string{"status", "title", "quota"}},
+	})
+	baseline := detector.Evaluate(SnapshotSet{}, []Observation{
+		fieldObservation("/a/1", map[string]string{"title": "编程大赛报名", "status": "报名中", "quota": "100"}),
+	})
+	if len(baseline.Events) != 0 {
+		t.Fatalf("new items should not produce events, got %+v", baseline.Events)
+	}
+	previous := make(SnapshotSet)
+	for _, snapshot := range baseline.NextSnapshots {
+		previous[snapshot.ItemKey] = snapshot
+	}
+
+	result := detector.Evaluate(previous, []Observation{
+		fieldObservation("/a/1", map[string]string{"title": "编程大赛报名（延期）", "status": "已截止", "quota": ""}),
+	})
+	if len(result.Events) != 2 {
+		t.Fatalf("expected status and title events, got %+v", result.Events)
+	}
+	byField := make(map[string]ChangeEvent)
+	for _, event := range result.Events {
+		if event.EventType != "field_changed" || event.Title != "编程大赛报名（延期）" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+		byField[event.Field] = event
+	}
+	if status := byField["status"]; status.OldValue != "报名中" || status.NewValue != "已截止" {
+		t.Errorf("unexpected status event: %+v", status)
+	}
+	if title := byField["title"]; title.OldValue != "编程大赛报名" || title.NewValue != "编程大赛报名（延期）" {
+		t.Errorf("unexpected title event: %+v", title)
+	}
+	// 未提取到的字段沿用旧值，恢复后不产生事件
+	if quota := result.NextSnapshots[0].Payload["quota"]; quota != "100" {
+		t.Errorf("missing value should keep the previous one, got %v", quota)
+	}
+}
+
+func TestWatchConfigComparisonOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		watch    WatchConfig
+		old, new string
+		same     bool
+	}{
+		{"exact", WatchConfig{}, "报名中", "报名中 ", false},
+		{"whitespace", WatchConfig{IgnoreWhitespace: true}, "报名 中", " 报名中\n", true},
+		{"case", WatchConfig{IgnoreCase: true}, "Open", "OPEN", true},
+		{"case sensitive", WatchConfig{}, "Open", "OPEN", false},
+		{"number format", WatchConfig{IgnoreNumberFormat: true}, "剩余 1,200.50 元", "剩余 1200.5 元", true},
+		{"leading zeros", WatchConfig{IgnoreNumberFormat: true}, "第 007 期", "第 7 期", true},
+		{"number changed", WatchConfig{IgnoreNumberFormat: true}, "剩余 1,200 元", "剩余 1,201 元", false},
+		{"comma separator", WatchConfig{IgnoreNumberFormat: true}, "第3,4期", "第34期", false},
+		{"comma list", WatchConfig{IgnoreNumberFormat: true}, "座位 1,2,3", "座位 123", false},
+		{"grouped millions", WatchConfig{IgnoreNumberFormat: true}, "共 1,234,567 人", "共 1234567 人", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.watch.comparable(tt.old) == tt.watch.comparable(tt.new); got != tt.same {
+				t.Fatalf("comparable(%q) == comparable(%q) = %v, want %v", tt.old, tt.new, got, tt.same)
+			}
+		})
+	}
+}
+
+func TestNormalizeAndValidateSiteDefinitionFieldChanged(t *testing.T) {
+	newSite := func(config string) *database.Site {
+		return &database.Site{
+			Name: "notices", URL: "https://example.com/notices", Container: "ul", Item: "li",
+			StrategyType: "field_changed", StrategyConfig: config,
+			Fields: []database.SiteField{
+				{Name: "title", Selector: "a", Type: "text"},
+				{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+				{Name: "status", Selector: ".status", Type: "text"},
+			},
+		}
+	}
+	valid := newSite(`{"type":"field_changed","identity":{"field":"url"},"watch":{"fields":["status","title"],"ignore_whitespace":true}}`)
+	if err := NormalizeAndValidateSiteDefinition(valid); err != nil {
+		t.Fatalf("expected field_changed definition to be valid: %v", err)
+	}
+	if _, err := NewEngine(valid); err != nil {
+		t.Fatalf("expected engine for field_changed: %v", err)
+	}
+	invalid := []string{
+		`{"type":"field_changed","identity":{"field":"url"}}`,
+		`{"type":"field_changed","identity":{"field":"url"},"watch":{"fields":["missing"]}}`,
+		`{"type":"field_changed","identity":{"field":"url"},"watch":{"fields":["url"]}}`,
+		`{"type":"field_changed","identity":{"field":"url"},"watch":{"fields":["status","status"]}}`,
+	}
+	for _, config := range invalid {
+		if err := NormalizeAndValidateSiteDefinition(newSite(config)); err == nil {
+			t.Errorf("expected %s to be rejected", config)
+		}
+	}
+}
+
+func TestCheckOncePersistsFieldChangedEvents(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var closed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if closed.Load() {
+			_, _ = w.Write([]byte(`<ul><li><a href="/a/1">编程大赛</a><span class="status">已截止</span><span class="seats">0</span></li></ul>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<ul><li><a href="/a/1">编程大赛</a><span class="status">报名中</span><span class="seats">12</span></li></ul>`))
+	}))
+	defer server.Close()
+
+	site := &database.Site{
+		Name: "notice-status", URL: server.URL, Container: "ul", Item: "li",
+		StrategyType:   "field_changed",
+		StrategyConfig: `{"type":"field_changed","identity":{"field":"url"},"watch":{"fields":["status","seats"]},"on_first_baseline":"silent"}`,
+		ConfigVersion:  1,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+			{Name: "status", Selector: ".status", Type: "text"},
+			{Name: "seats", Selector: ".seats", Type: "text"},
+		},
+	}
+	if err := database.CreateSiteWithFields(site); err != nil {
+		t.Fatalf("create site: %v", err)
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, isFirst, err := engine.CheckOnce(context.Background()); err != nil || !isFirst {
+		t.Fatalf("baseline check: first=%v err=%v", isFirst, err)
+	}
+	closed.Store(true)
+	events, _, err := engine.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("check after change: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected two field events, got %+v", events)
+	}
+
+	var stored []database.MonitorEvent
+	if err := database.GetDB().Where("site_id = ? AND event_type = ?", site.ID, "field_changed").Order("field_name").Find(&stored).Error; err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	if len(stored) != 2 || stored[0].FieldName != "seats" || stored[1].FieldName != "status" {
+		t.Fatalf("both field changes should be stored separately, got %+v", stored)
+	}
+	if stored[1].OldValue != "报名中" || stored[1].NewValue != "已截止" {
+		t.Fatalf("unexpected status event: %+v", stored[1])
+	}
+
+	title, content := FormatEvent(ChangeEvent{EventType: "field_changed", Title: "编程大赛", Field: "status", OldValue: "报名中", NewValue: "已截止"}, "竞赛通知")
+	if title != "竞赛通知 字段变化: 编程大赛" || content == "" {
+		t.Fatalf("unexpected formatted event: %q %q", title, content)
+	}
+}
@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep JSONPath 的单个路径段
+type jsonPathStep struct {
+	key       string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool // 由 .. 引入，匹配任意深度的后代
+}
+
+// jsonPath 编译后的 JSONPath 表达式，支持的子集：
+//
+//	$ / @          当前节点（可省略）
+//	.name ['name'] 对象成员
+//	[0] [-1]       数组下标，负数从末尾计数
+//	.* [*]         所有成员或元素
+//	..name ..*     递归下降
+type jsonPath []jsonPathStep
+
+// compileJSONPath 解析 JSONPath 表达式，空表达式表示当前节点。
+func compileJSONPath(expr string) (jsonPath, error) {
+	s := strings.TrimSpace(expr)
+	first := true
+	if strings.HasPrefix(s, "$") || strings.HasPrefix(s, "@") {
+		s = s[1:]
+		first = false
+	}
+	var path jsonPath
+	for len(s) > 0 {
+		recursive := false
+		switch {
+		case strings.HasPrefix(s, ".."):
+			recursive = true
+			s = s[2:]
+		case s[0] == '.':
+			s = s[1:]
+		case s[0] == '[':
+		case first:
+			// 允许相对路径省略开头的点，如 items[*]
+		default:
+			return nil, fmt.Errorf("JSONPath %q 在 %q 附近无效", expr, s)
+		}
+		first = false
+
+		var step jsonPathStep
+		if strings.HasPrefix(s, "[") {
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q 缺少 ]", expr)
+			}
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			switch {
+			case inner == "*":
+				step.wildcard = true
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				step.key = inner[1 : len(inner)-1]
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath %q 的下标无效: %s", expr, inner)
+				}
+				step.index = index
+				step.isIndex = true
+			}
+		} else {
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			name := strings.TrimSpace(s[:end])
+			s = s[end:]
+			if name == "" {
+				return nil, fmt.Errorf("JSONPath %q 包含空的成员名", expr)
+			}
+			if name == "*" {
+				step.wildcard = true
+			} else {
+				step.key = name
+			}
+		}
+		step.recursive = recursive
+		path = append(path, step)
+	}
+	return path, nil
+}
+
+// eval 返回从 root 出发匹配到的所有节点，顺序确定。
+func (p jsonPath) eval(root interface{}) []interface{} {
+	nodes := []interface{}{root}
+	for _, step := range p {
+		var next []interface{}
+		for _, node := range nodes {
+			candidates := []interface{}{node}
+			if step.recursive {
+				candidates = jsonDescendants(node, nil)
+			}
+			for _, candidate := range candidates {
+				next = step.apply(candidate, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (step jsonPathStep) apply(node interface{}, out []interface{}) []interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if step.wildcard {
+			for _, key := range sortedJSONKeys(value) {
+				out = append(out, value[key])
+			}
+		} else if !step.isIndex {
+			if child, ok := value[step.key]; ok {
+				out = append(out, child)
+			}
+		}
+	case []interface{}:
+		if step.wildcard {
+			out = append(out, value...)
+		} else if step.isIndex {
+			index := step.index
+			if index < 0 {
+				index += len(value)
+			}
+			if index >= 0 && index < len(value) {
+				out = append(out, value[index])
+			}
+		}
+	}
+	return out
+}
+
+// jsonDescendants 先序收集节点自身及其所有后代。
+func jsonDescendants(node interface{}, out []interface{}) []interface{} {
+	out = append(out, node)
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedJSONKeys(value) {
+			out = jsonDescendants(value[key], out)
+		}
+	case []interface{}:
+		for _, child := range value {
+			out = jsonDescendants(child, out)
+		}
+	}
+	return out
+}
+
+func sortedJSONKeys(value map[string]interface{}) []string {
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonScalarText 将 JSON 节点转换为字段文本；对象和数组输出紧凑 JSON，null 视为缺失。
+func jsonScalarText(node interface{}) (string, bool) {
+	switch value := node.(type) {
+	case nil:
+		return "", false
+	case string:
+		return strings.TrimSpace(value), true
+	case json.Number:
+		return value.String(), true
+	case bool:
+		return strconv.FormatBool(value), true
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func TestCompileJSONPath(t *testing.T) {
+	root := map[string]interface{}{
+		"data": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"id": "a", "tags": []interface{}{"x"}},
+				map[string]interface{}{"id": "b", "tags": []interface{}{"y", "z"}},
+			},
+		},
+		"id": "root",
+	}
+	tests := []struct {
+		expr string
+		want []interface{}
+	}{
+		{"", []interface{}{root}},
+		{"$.data.list[0].id", []interface{}{"a"}},
+		{"data.list[-1]['id']", []interface{}{"b"}},
+		{"$.data.list[*].id", []interface{}{"a", "b"}},
+		{"$..id", []interface{}{"root", "a", "b"}},
+		{"$.data.list[*].tags[*]", []interface{}{"x", "y", "z"}},
+		{"$.missing.id", nil},
+	}
+	for _, tt := range tests {
+		path, err := compileJSONPath(tt.expr)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.expr, err)
+		}
+		if got := path.eval(root); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"$.list[", "$.list[abc]", "$.a..", "$a"} {
+		if _, err := compileJSONPath(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestJSONModeExtractsPriceObservations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"items":[{"sku":"A1","name":"商品 A","price":{"amount":199.00,"text":"¥199.00"},"link":"/p/A1"},{"sku":"B2","name":"商品 B","price":{"amount":5,"text":"¥5.00"},"link":"/p/B2"}]}}`))
+	}))
+	defer server.Close()
+
+	site := &database.Site{
+		Name: "json-price", URL: server.URL, ExtractMode: "JSON", Container: "$.data.items[*]", StrategyType: "field_transition",
+		StrategyConfig: `{"type":"field_transition","identity":{"field":"sku"},"conditions":[{"field":"price","value_type":"money","operator":"decreased"}],"on_first_baseline":"silent"}`,
+		FieldDataTypes: `{"price":"money"}`,
+		Fields: []database.SiteField{
+			{Name: "sku", Selector: "sku", Type: "text"},
+			{Name: "title", Selector: "name", Type: "text"},
+			{Name: "price", Selector: "price", Type: "attr", Attr: "text"},
+			{Name: "url", Selector: "$.link", Type: "text"},
+		},
+	}
+	if err := NormalizeAndValidateSiteDefinition(site); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if site.ExtractMode != ExtractModeJSON {
+		t.Fatalf("extract mode should be normalized, got %q", site.ExtractMode)
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	observations, err := engine.observe(context.Background())
+	if err != nil {
+		t.Fatalf("observe: %v", err)
+	}
+	if len(observations) != 2 || observations[0].ItemKey != "A1" || observations[1].ItemKey != "B2" {
+		t.Fatalf("unexpected observations: %+v", observations)
+	}
+	price := observations[0].Fields["price"]
+	if !price.Valid || price.Minor != 19900 || price.Currency != "CNY" {
+		t.Fatalf("unexpected money normalization: %+v", price)
+	}
+	if observations[1].Raw["url"] != server.URL+"/p/B2" {
+		t.Fatalf("relative URLs should resolve against the site URL: %v", observations[1].Raw["url"])
+	}
+}
+
+func TestJSONModeRejectsInvalidPaths(t *testing.T) {
+	site := &database.Site{
+		Name: "json-invalid", URL: "https://example.com/api", ExtractMode: "json", Container: "$.items[", StrategyType: "presence",
+		StrategyConfig: `{"type":"presence","identity":{"source":"source_url"},"on_first_baseline":"silent"}`,
+		Fields:         []database.SiteField{{Name: "title", Selector: "title", Type: "text"}},
+	}
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil {
+		t.Fatal("expected invalid container path to be rejected")
+	}
+	site.Container = "$.items[*]"
+	site.Fields[0].Selector = "title[x]"
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil {
+		t.Fatal("expected invalid field path to be rejected")
+	}
+	site.Fields[0].Selector = "title"
+	site.ExtractMode = "xml"
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil {
+		t.Fatal("expected unknown extract mode to be rejected")
+	}
+}
+
+func TestJSONModeListAndCountFields(t *testing.T) {
+	body := `{"items":[{"tags":["新品","包邮"],"stores":[{"city":"上海"},{"city":"北京"}]},{"tags":[],"stores":[]}]}`
+	ex := NewExtractor(SiteSelectors{
+		Mode:      ExtractModeJSON,
+		Container: "items[*]",
+		Fields: []FieldConfig{
+			{Name: "tags", Selector: "tags[*]", Type: FieldTypeList},
+			{Name: "cities", Selector: "stores[*]", Type: FieldTypeList, Attr: "city"},
+			{Name: "stores", Selector: "stores[*]", Type: FieldTypeCount},
+		},
+	})
+	items, err := ex.Extract(body)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if !reflect.DeepEqual(items[0]["tags"], []string{"新品", "包邮"}) || !reflect.DeepEqual(items[0]["cities"], []string{"上海", "北京"}) || items[0]["stores"] != "2" {
+		t.Fatalf("unexpected first item: %#v", items[0])
+	}
+	if _, ok := items[1]["tags"]; ok || items[1]["stores"] != "0" {
+		t.Fatalf("unexpected second item: %#v", items[1])
+	}
+}
@@ -23,6 +23,8 @@ type MonitorStatus struct {
 	LastCheck      time.Time     `json:"last_check"`
 	LastDuration   time.Duration `json:"last_duration"`
 	LastError      string        `json:"last_error,omitempty"`
+	LastErrorClass string        `json:"last_error_class,omitempty"`
+	Blocked        bool          `json:"blocked"` // 最近一次检查被目标站点拦截（拒绝访问或验证页）
 	LastUpdate     time.Time     `json:"last_update,omitempty"`
 	UpdatesCount   int           `json:"updates_count"`
 	NextCheck      time.Time     `json:"next_check"`
@@ -31,6 +33,8 @@ type MonitorStatus struct {
 	BaselineStatus string        `json:"baseline_status,omitempty"`
 	LastEventAt    *time.Time    `json:"last_event_at,omitempty"`
 	SnapshotCount  int           `json:"snapshot_count,omitempty"`
+	// FieldStats 最近一次完成提取的检查中各字段的命中情况
+	FieldStats []FieldHitStat `json:"field_stats,omitempty"`
 }
 
 // AtomicReplaceMonitor 原子式替换监控器：停止旧实例 → 注销旧名 → 创建新实例 → 启动/停止
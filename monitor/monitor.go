@@ -16,20 +16,21 @@ import (
 )
 
 type Monitor struct {
-	site        *database.Site
-	siteLock    sync.RWMutex
-	extractor   *Extractor
-	fetcher     *fetcher.Fetcher
-	stopCh      chan struct{}
-	stopOnce    sync.Once
-	checkGate   chan struct{}
-	cancelLock  sync.Mutex
-	checkCancel context.CancelFunc
-	runLock     sync.Mutex
-	runStarted  bool
-	runDone     chan struct{}
-	status      MonitorStatus
-	statusLock  sync.RWMutex
+	site            *database.Site
+	siteLock        sync.RWMutex
+	extractor       *Extractor
+	detailExtractor *Extractor
+	fetcher         *fetcher.Fetcher
+	stopCh          chan struct{}
+	stopOnce        sync.Once
+	checkGate       chan struct{}
+	cancelLock      sync.Mutex
+	checkCancel     context.CancelFunc
+	runLock         sync.Mutex
+	runStarted      bool
+	runDone         chan struct{}
+	status          MonitorStatus
+	statusLock      sync.RWMutex
 }
 
 type CheckOutcome struct {
@@ -37,34 +38,24 @@ type CheckOutcome struct {
 	Events          []ChangeEvent   `json:"events,omitempty"`
 	Updates         []ExtractResult `json:"updates,omitempty"`
 	IsFirstBaseline bool            `json:"is_first_baseline"`
+	// FieldStats 本次检查各字段的命中情况，页面未变化或检查失败时为空
+	FieldStats []FieldHitStat `json:"field_stats,omitempty"`
 }
 
 func newMonitor(site *database.Site, fetcherOpts ...fetcher.Option) *Monitor {
 	f := fetcher.New(fetcherOpts...)
 
-	// 从 database.Site 构建选择器信息
-	selectors := SiteSelectors{
-		Container: site.Container,
-		Item:      site.Item,
-		Fields:    make([]FieldConfig, len(site.Fields)),
-	}
-	for i, f := range site.Fields {
-		selectors.Fields[i] = FieldConfig{
-			Name:      f.Name,
-			Selector:  f.Selector,
-			Type:      f.Type,
-			Attr:      f.Attr,
-			Transform: f.Transform,
-		}
-	}
+	// 从 database.Site 构建选择器信息，详情页字段单独提取
+	selectors := listSelectors(site)
 
 	m := &Monitor{
-		site:      site,
-		extractor: NewExtractor(selectors),
-		fetcher:   f,
-		stopCh:    make(chan struct{}),
-		checkGate: make(chan struct{}, 1),
-		runDone:   make(chan struct{}),
+		site:            site,
+		extractor:       NewExtractor(selectors),
+		detailExtractor: detailExtractor(site.Fields),
+		fetcher:         f,
+		stopCh:          make(chan struct{}),
+		checkGate:       make(chan struct{}, 1),
+		runDone:         make(chan struct{}),
 		status: MonitorStatus{
 			Name:           site.Name,
 			URL:            site.URL,
@@ -141,6 +132,7 @@ func performCheck(m *Monitor, isFirst bool) {
 			log.Printf("[%s] 检查异常: %v", m.siteName(), r)
 			m.updateStatus(func(s *MonitorStatus) {
 				s.LastError = fmt.Sprintf("panic: %v", r)
+				s.LastErrorClass = fetcher.ErrorClassOther
 			})
 		}
 	}()
@@ -148,7 +140,7 @@ func performCheck(m *Monitor, isFirst bool) {
 	startTime := time.Now()
 	outcome, err := m.CheckNow(context.Background())
 	duration := time.Since(startTime)
-	if outcome.StrategyType == "field_transition" {
+	if UsesEventEngine(outcome.StrategyType) {
 		logCheckResultFromEngine(m, outcome.Events, err, duration, isFirst)
 		if err == nil && len(outcome.Events) > 0 {
 			log.Printf("[%s] 产生 %d 个事件，等待投递队列处理", m.siteName(), len(outcome.Events))
@@ -174,7 +166,7 @@ func (m *Monitor) CheckNow(ctx context.Context) (CheckOutcome, error) {
 	}
 	outcome := CheckOutcome{StrategyType: strategyType}
 
-	if strategyType == "field_transition" {
+	if UsesEventEngine(strategyType) {
 		engine, createErr := NewEngine(&site)
 		if createErr != nil {
 			updateMonitorStatusFromEngine(m, nil, createErr, time.Since(startTime))
@@ -183,6 +175,8 @@ func (m *Monitor) CheckNow(ctx context.Context) (CheckOutcome, error) {
 		events, isFirstBaseline, checkErr := engine.CheckOnce(checkCtx)
 		outcome.Events = events
 		outcome.IsFirstBaseline = isFirstBaseline
+		outcome.FieldStats = engine.FieldStats()
+		m.setFieldStats(outcome.FieldStats)
 		updateMonitorStatusFromEngine(m, events, checkErr, time.Since(startTime))
 		if isFirstBaseline && checkErr == nil {
 			m.SetBaselineStatus("ready")
@@ -190,8 +184,10 @@ func (m *Monitor) CheckNow(ctx context.Context) (CheckOutcome, error) {
 		return outcome, checkErr
 	}
 
-	updates, checkErr := m.checkForUpdatesContext(checkCtx, site)
+	updates, fieldStats, checkErr := m.checkForUpdatesContext(checkCtx, site)
 	outcome.Updates = updates
+	outcome.FieldStats = fieldStats
+	m.setFieldStats(fieldStats)
 	if checkErr == nil && site.BaselineStatus != "ready" {
 		if err := database.GetDB().Model(&database.Site{}).Where("id = ?", site.ID).Update("baseline_status", "ready").Error; err != nil {
 			checkErr = fmt.Errorf("更新基线状态失败: %w", err)
@@ -237,6 +233,8 @@ func updateMonitorStatus(m *Monitor, updates []ExtractResult, err error, duratio
 		s.LastDuration = duration
 		s.NextCheck = time.Now().Add(site.GetCheckInterval())
 
+		s.LastErrorClass = fetcher.ClassifyError(err)
+		s.Blocked = s.LastErrorClass == fetcher.ErrorClassBlocked
 		if err != nil {
 			s.LastError = err.Error()
 		} else {
@@ -261,6 +259,8 @@ func updateMonitorStatusFromEngine(m *Monitor, events []ChangeEvent, err error,
 		s.LastDuration = duration
 		s.NextCheck = time.Now().Add(site.GetCheckInterval())
 
+		s.LastErrorClass = fetcher.ClassifyError(err)
+		s.Blocked = s.LastErrorClass == fetcher.ErrorClassBlocked
 		if err != nil {
 			s.LastError = err.Error()
 		} else {
@@ -322,26 +322,65 @@ func logCheckResultFromEngine(m *Monitor, events []ChangeEvent, err error, durat
 }
 
 func (m *Monitor) CheckForUpdates() ([]ExtractResult, error) {
-	return m.checkForUpdatesContext(context.Background(), m.siteSnapshot())
+	updates, _, err := m.checkForUpdatesContext(context.Background(), m.siteSnapshot())
+	return updates, err
+}
+
+// setFieldStats 记录最近一次完成提取的字段命中情况，页面未变化或检查失败时保留上次结果。
+func (m *Monitor) setFieldStats(stats []FieldHitStat) {
+	if len(stats) == 0 {
+		return
+	}
+	m.updateStatus(func(s *MonitorStatus) { s.FieldStats = stats })
 }
 
-func (m *Monitor) checkForUpdatesContext(ctx context.Context, site database.Site) ([]ExtractResult, error) {
-	html, err := m.fetcher.FetchContext(ctx, site.URL)
+func (m *Monitor) checkForUpdatesContext(ctx context.Context, site database.Site) ([]ExtractResult, []FieldHitStat, error) {
+	fetchConfig, err := ParseFetchConfig(site.FetchConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid fetch config: %w", err)
+	}
+	rule, err := ParseDetectionRule(site.StrategyConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := database.LoadFetchState(site.ID, site.ConfigVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	req := fetchConfig.Request(site.URL)
+	if state != nil {
+		req.IfNoneMatch = state.ETag
+		req.IfModifiedSince = state.LastModified
+	}
+	source := fetchConfig.siteSource(&site, m.fetcher)
+	resp, err := source.Do(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("fetch failed: %w", err)
+		return nil, nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	// 页面未变化，沿用上次结果
+	if resp.NotModified() {
+		return nil, nil, nil
 	}
 
-	current, err := m.extractor.Extract(html)
+	pages, err := extractPages(ctx, source, fetchConfig, m.extractor, site.URL, resp, newPageArchiver(&site, fetchConfig))
 	if err != nil {
-		return nil, fmt.Errorf("extraction failed: %w", err)
+		return nil, nil, err
 	}
-	if err := ResolveExtractedURLs(site.URL, current); err != nil {
-		return nil, fmt.Errorf("resolve extracted URLs failed: %w", err)
+	var current []ExtractResult
+	for _, results := range pages {
+		current = append(current, results...)
 	}
 
 	last, err := m.loadLastResults()
 	if err != nil {
-		return nil, fmt.Errorf("load history failed: %w", err)
+		return nil, nil, fmt.Errorf("load history failed: %w", err)
+	}
+	// 已有记录的页面突然提取不到任何条目，多半是页面改版导致选择器漂移
+	if len(current) == 0 && len(last) > 0 {
+		if repair := proposeSelectorRepair(&site, resp.Body, ErrNoItems.Error(), titleKeywords(last), historyMatcher(last)); repair != nil {
+			return nil, nil, fmt.Errorf("%w（已生成选择器修复建议 #%d）", ErrNoItems, repair.ID)
+		}
+		return nil, nil, ErrNoItems
 	}
 
 	newItems := compareResults(last, current)
@@ -349,14 +388,21 @@ func (m *Monitor) checkForUpdatesContext(ctx context.Context, site database.Site
 	if len(last) == 0 {
 		newItems = nil
 	}
+	// 只为新增条目抓取详情页，newItems 与 current 共享条目，补充字段随结果一并保存
+	fetchDetails(ctx, source, fetchConfig, m.detailExtractor, site.Name, newItems)
+	fieldStats := siteFieldHitStats(&site, current, newItems, siteFieldDataTypes(&site))
+	// 发布时间早于 max_age_days 的旧条目重新出现在列表中时不通知，仍随 current 记入基线
+	newItems = rule.dropStale(newItems, time.Now())
 
 	// saveResults 保存所有当前结果到数据库（含 title+url 去重），
 	// 新条目会被记录为新 UpdateRecord，已存在的跳过
 	if err := m.saveResults(current); err != nil {
-		return nil, fmt.Errorf("save failed: %w", err)
+		return nil, nil, fmt.Errorf("save failed: %w", err)
 	}
+	saveFetchValidators(&site, resp)
+	fieldStats = recordFieldHitStats(&site, len(current), fieldStats)
 
-	return newItems, nil
+	return newItems, fieldStats, nil
 }
 
 // ResolveExtractedURLs 将提取结果中的相对链接转换为监控源站的绝对链接。
@@ -385,8 +431,18 @@ func toString(v interface{}) string {
 	if v == nil {
 		return ""
 	}
-	if s, ok := v.(string); ok {
-		return s
+	switch value := v.(type) {
+	case string:
+		return value
+	case []string:
+		// list 字段按原顺序拼接
+		return strings.Join(value, ", ")
+	case []interface{}:
+		parts := make([]string, 0, len(value))
+		for _, item := range value {
+			parts = append(parts, toString(item))
+		}
+		return strings.Join(parts, ", ")
 	}
 	return fmt.Sprintf("%v", v)
 }
@@ -476,17 +532,23 @@ func (m *Monitor) siteName() string {
 }
 
 func (m *Monitor) loadLastResults() ([]ExtractResult, error) {
-	// 查询所有 distinct (title, url) 用于去重，比加载全量 Content 更高效
+	return loadLastResults(m.site)
+}
+
+// loadLastResults 加载站点已记录条目的身份信息，供新增检测比较。
+func loadLastResults(site *database.Site) ([]ExtractResult, error) {
+	// 查询所有 distinct (title, url, item_key) 用于去重，比加载全量 Content 更高效
 	type keyPair struct {
-		Title string
-		URL   string
+		Title   string
+		URL     string
+		ItemKey string
 	}
 	var keys []keyPair
 	if err := database.GetDB().Model(&database.UpdateRecord{}).
-		Select("DISTINCT title, url").
-		Where("site_id = ?", m.site.ID).
+		Select("DISTINCT title, url, item_key").
+		Where("site_id = ?", site.ID).
 		Find(&keys).Error; err != nil {
-		log.Printf("[%s] 加载历史结果失败: %v", m.site.Name, err)
+		log.Printf("[%s] 加载历史结果失败: %v", site.Name, err)
 		return nil, fmt.Errorf("load history failed: %w", err)
 	}
 
@@ -496,7 +558,9 @@ func (m *Monitor) loadLastResults() ([]ExtractResult, error) {
 
 	var results []ExtractResult
 	for _, k := range keys {
-		if k.Title != "" || k.URL != "" {
+		if k.ItemKey != "" {
+			results = append(results, ExtractResult{"title": k.Title, "url": k.URL, "guid": k.ItemKey})
+		} else if k.Title != "" || k.URL != "" {
 			results = append(results, ExtractResult{"title": k.Title, "url": k.URL})
 		}
 	}
@@ -508,14 +572,15 @@ func (m *Monitor) saveResults(results []ExtractResult) error {
 		return nil
 	}
 
-	// 一次性加载已有的 (title, url) 对，避免 N+1 查询
+	// 一次性加载已有的 (title, url, item_key)，避免 N+1 查询
 	type keyPair struct {
-		Title string
-		URL   string
+		Title   string
+		URL     string
+		ItemKey string
 	}
 	var existing []keyPair
 	if err := database.GetDB().Model(&database.UpdateRecord{}).
-		Select("DISTINCT title, url").
+		Select("DISTINCT title, url, item_key").
 		Where("site_id = ?", m.site.ID).
 		Find(&existing).Error; err != nil {
 		log.Printf("[%s] 加载已有记录失败: %v", m.site.Name, err)
@@ -524,7 +589,9 @@ func (m *Monitor) saveResults(results []ExtractResult) error {
 
 	existingSet := make(map[string]struct{}, len(existing))
 	for _, k := range existing {
-		if k.Title != "" || k.URL != "" {
+		if k.ItemKey != "" {
+			existingSet["guid:"+k.ItemKey] = struct{}{}
+		} else if k.Title != "" || k.URL != "" {
 			existingSet[k.Title+"|"+k.URL] = struct{}{}
 		}
 	}
@@ -533,7 +600,11 @@ func (m *Monitor) saveResults(results []ExtractResult) error {
 	for _, item := range results {
 		title := toString(item["title"])
 		urlStr := toString(item["url"])
+		itemKey := toString(item["guid"])
 		key := title + "|" + urlStr
+		if itemKey != "" {
+			key = "guid:" + itemKey
+		}
 		if _, exists := existingSet[key]; exists {
 			continue
 		}
@@ -550,6 +621,7 @@ func (m *Monitor) saveResults(results []ExtractResult) error {
 			SiteID:  m.site.ID,
 			Title:   title,
 			URL:     urlStr,
+			ItemKey: itemKey,
 			Content: string(data),
 		}
 		if err := database.GetDB().Create(record).Error; err != nil {
@@ -581,14 +653,20 @@ func compareResults(last, current []ExtractResult) []ExtractResult {
 	return newItems
 }
 
-// matchKeywords 检查更新项的标题或URL是否命中任一关键词（大小写不敏感）
-func matchKeywords(item ExtractResult, keywordList []string) bool {
+// matchKeywords 检查更新项的标题、URL或指定的详情页字段是否命中任一关键词（大小写不敏感）
+func matchKeywords(item ExtractResult, keywordList []string, detailNames ...string) bool {
 	if len(keywordList) == 0 {
 		return true
 	}
 	title, _ := item["title"].(string)
 	urlStr, _ := item["url"].(string)
-	text := strings.ToLower(title + " " + urlStr)
+	text := title + " " + urlStr
+	for _, name := range detailNames {
+		if value, ok := item[name].(string); ok {
+			text += " " + value
+		}
+	}
+	text = strings.ToLower(text)
 	if text == "" {
 		return false
 	}
@@ -605,27 +683,32 @@ func matchKeywords(item ExtractResult, keywordList []string) bool {
 }
 
 // filterByKeywords 根据关键词过滤更新项，仅返回命中任一关键词的项
-func filterByKeywords(items []ExtractResult, keywords string) []ExtractResult {
+func filterByKeywords(items []ExtractResult, keywords string, detailNames ...string) []ExtractResult {
 	if keywords == "" {
 		return items
 	}
 	kwList := strings.Split(keywords, ",")
 	var matched []ExtractResult
 	for _, item := range items {
-		if matchKeywords(item, kwList) {
+		if matchKeywords(item, kwList, detailNames...) {
 			matched = append(matched, item)
 		}
 	}
 	return matched
 }
 
-func buildNotifyContent(siteName string, items []ExtractResult) (string, string) {
+func buildNotifyContent(siteName string, items []ExtractResult, detailNames ...string) (string, string) {
 	// 推送给前端但前端不需要 content，保持原有格式
 	title := fmt.Sprintf("%s 有 %d 条更新", siteName, len(items))
 	var content strings.Builder
 	content.WriteString("最新更新内容：\n")
 	for i, item := range items {
 		fmt.Fprintf(&content, "%d. %s\n   %s\n", i+1, item["title"], item["url"])
+		for _, name := range detailNames {
+			if value := toString(item[name]); value != "" {
+				fmt.Fprintf(&content, "   %s: %s\n", name, value)
+			}
+		}
 	}
 	return title, content.String()
 }
@@ -639,7 +722,7 @@ func (m *Monitor) sendCombinedNotification(items []ExtractResult) {
 
 	// 如果启用了关键词过滤，只推送命中关键词的更新
 	if site.NotifyFilter == "keyword" && site.NotifyKeywords != "" {
-		matched := filterByKeywords(items, site.NotifyKeywords)
+		matched := filterByKeywords(items, site.NotifyKeywords, detailFieldNames(site.Fields)...)
 		if len(matched) == 0 {
 			log.Printf("[%s] 关键词过滤后无匹配项，跳过推送", site.Name)
 			return
@@ -654,7 +737,7 @@ func (m *Monitor) sendCombinedNotification(items []ExtractResult) {
 		return
 	}
 
-	title, content := buildNotifyContent(site.Name, items)
+	title, content := buildNotifyContent(site.Name, items, detailFieldNames(site.Fields)...)
 
 	var sentCount int
 	var failedAccounts []string
@@ -709,6 +792,10 @@ func (m *Monitor) sendCombinedNotification(items []ExtractResult) {
 }
 
 func extractKey(item ExtractResult) string {
+	// 订阅源条目以 guid 作为身份，标题或链接修改不视为新增
+	if guid, _ := item["guid"].(string); guid != "" {
+		return "guid:" + guid
+	}
 	title, _ := item["title"].(string)
 	urlStr, _ := item["url"].(string)
 	switch {
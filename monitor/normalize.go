@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -29,9 +30,19 @@ var (
 	numericTokenRegex = regexp.MustCompile(`\d+(?:[.,]\d+)*`)
 )
 
-// NormalizeField 根据字段类型规范化值
+// NormalizeField 根据字段类型规范化值，datetime 类型按服务器时区解析
 func NormalizeField(value string, dataType string) TypedValue {
+	return NormalizeFieldIn(value, dataType, nil)
+}
+
+// NormalizeFieldIn 与 NormalizeField 相同，datetime 类型按 loc 时区解析（nil 表示服务器时区）
+func NormalizeFieldIn(value string, dataType string, loc *time.Location) TypedValue {
 	switch dataType {
+	case "datetime":
+		if loc == nil {
+			loc = time.Local
+		}
+		return normalizeDateTime(value, loc, time.Now())
 	case "money":
 		return normalizeMoney(value)
 	case "decimal":
@@ -277,7 +288,7 @@ func GenerateItemKey(obs ExtractResult, identity IdentityConfig, sourceURL strin
 	}
 	if identity.Field != "" {
 		if v, ok := obs[identity.Field]; ok {
-			return strings.TrimSpace(fmt.Sprintf("%v", v))
+			return strings.TrimSpace(toString(v))
 		}
 	}
 	if len(identity.Fields) > 0 {
@@ -287,7 +298,7 @@ func GenerateItemKey(obs ExtractResult, identity IdentityConfig, sourceURL strin
 			if !ok {
 				return ""
 			}
-			part := strings.TrimSpace(fmt.Sprintf("%v", v))
+			part := strings.TrimSpace(toString(v))
 			if part == "" {
 				return ""
 			}
@@ -300,12 +311,44 @@ func GenerateItemKey(obs ExtractResult, identity IdentityConfig, sourceURL strin
 	return ""
 }
 
-// ComputeFingerprint 计算字段哈希用于快速比较
+// ComputeFingerprint 计算字段哈希用于快速比较。
+// list 字段按值排序后参与计算，标签等多值字段仅顺序变化时指纹不变；
+// 从快照还原的 []interface{} 与提取得到的 []string 结果相同。
 func ComputeFingerprint(fields map[string]interface{}) string {
-	data, err := json.Marshal(fields)
+	canonical := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		canonical[name] = fingerprintValue(value)
+	}
+	data, err := json.Marshal(canonical)
 	if err != nil {
 		data = []byte(fmt.Sprintf("%v", fields))
 	}
 	sum := sha256.Sum256(data)
 	return fmt.Sprintf("%x", sum[:])
 }
+
+// fieldValueString 把提取值转换为参与比较的字符串：list 字段排序后拼接，
+// 使快照 payload 与指纹一样不受多值字段顺序影响。
+func fieldValueString(value interface{}) string {
+	switch value.(type) {
+	case []string, []interface{}:
+		return strings.Join(fingerprintValue(value).([]string), ", ")
+	}
+	return toString(value)
+}
+
+func fingerprintValue(value interface{}) interface{} {
+	var values []string
+	switch list := value.(type) {
+	case []string:
+		values = append(values, list...)
+	case []interface{}:
+		for _, item := range list {
+			values = append(values, toString(item))
+		}
+	default:
+		return value
+	}
+	sort.Strings(values)
+	return values
+}
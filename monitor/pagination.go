@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/cn-maul/Gentry/fetcher"
+)
+
+const (
+	defaultPaginationPages = 5
+	maxPaginationPages     = 20
+	maxPaginationDelayMS   = 60000
+)
+
+// PaginationConfig 多页列表抓取配置，next_selector 与 url_template 二选一。
+type PaginationConfig struct {
+	// NextSelector 下一页链接的 CSS 选择器（或 xpath: 前缀的 XPath），仅 HTML 提取模式可用
+	NextSelector string `json:"next_selector,omitempty"`
+	// URLTemplate 分页地址模板，{page} 依次替换为 2、3……
+	URLTemplate string `json:"url_template,omitempty"`
+	// MaxPages 最多抓取的页数（含首页），默认 5，上限 20
+	MaxPages int `json:"max_pages,omitempty"`
+	// DelayMS 相邻两页之间的等待时间（毫秒）
+	DelayMS int `json:"delay_ms,omitempty"`
+}
+
+func (p *PaginationConfig) normalize() error {
+	p.NextSelector = strings.TrimSpace(p.NextSelector)
+	p.URLTemplate = strings.TrimSpace(p.URLTemplate)
+	if (p.NextSelector == "") == (p.URLTemplate == "") {
+		return fmt.Errorf("分页配置必须且只能设置 next_selector 或 url_template 之一")
+	}
+	if err := ValidateSelector(p.NextSelector); err != nil {
+		return fmt.Errorf("下一页选择器: %w", err)
+	}
+	if p.URLTemplate != "" {
+		if !strings.Contains(p.URLTemplate, "{page}") {
+			return fmt.Errorf("分页地址模板必须包含 {page}")
+		}
+		parsed, err := url.Parse(strings.ReplaceAll(p.URLTemplate, "{page}", "2"))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("分页地址模板必须是 http 或 https 绝对地址")
+		}
+	}
+	switch {
+	case p.MaxPages == 0:
+		p.MaxPages = defaultPaginationPages
+	case p.MaxPages < 1 || p.MaxPages > maxPaginationPages:
+		return fmt.Errorf("分页最大页数必须在 1-%d 之间", maxPaginationPages)
+	}
+	if p.DelayMS < 0 || p.DelayMS > maxPaginationDelayMS {
+		return fmt.Errorf("分页间隔必须在 0-%d 毫秒之间", maxPaginationDelayMS)
+	}
+	return nil
+}
+
+// nextPageURL 返回第 page 页的地址，没有下一页时返回空字符串。
+func (p *PaginationConfig) nextPageURL(body, currentURL string, page int) string {
+	if p.URLTemplate != "" {
+		return strings.ReplaceAll(p.URLTemplate, "{page}", strconv.Itoa(page))
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	href, ok := FindSelection(doc.Selection, p.NextSelector).First().Attr("href")
+	if !ok || strings.TrimSpace(href) == "" {
+		return ""
+	}
+	base, err := url.Parse(currentURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return ""
+	}
+	next := base.ResolveReference(ref)
+	if next.Scheme != "http" && next.Scheme != "https" {
+		return ""
+	}
+	next.Fragment = ""
+	return next.String()
+}
+
+// extractPages 从首页响应开始按分页配置逐页抓取并提取，返回每页的结果（链接已解析为绝对地址）。
+// 未配置分页时只提取首页。遇到重复地址或没有下一页时提前结束。record 非空时在提取前接收每页响应。
+func extractPages(ctx context.Context, source fetcher.Source, config *FetchConfig, extractor *Extractor, firstURL string, first *fetcher.Response, record pageRecorder) ([][]ExtractResult, error) {
+	pageURL := firstURL
+	resp := first
+	body := first.Body
+	visited := map[string]bool{firstURL: true}
+	var pages [][]ExtractResult
+	for page := 1; ; page++ {
+		if record != nil {
+			record(page, pageURL, resp)
+		}
+		if err := config.detectChallenge(pageURL, resp); err != nil {
+			if page > 1 {
+				return nil, fmt.Errorf("第 %d 页%w", page, err)
+			}
+			return nil, err
+		}
+		results, err := extractor.Extract(body)
+		if err != nil {
+			if page > 1 {
+				return nil, fmt.Errorf("第 %d 页提取失败: %w", page, err)
+			}
+			return nil, fmt.Errorf("extraction failed: %w", err)
+		}
+		if err := ResolveExtractedURLs(pageURL, results); err != nil {
+			return nil, fmt.Errorf("resolve URLs failed: %w", err)
+		}
+		pages = append(pages, results)
+
+		pagination := config.Pagination
+		if pagination == nil || page >= pagination.MaxPages || len(results) == 0 {
+			return pages, nil
+		}
+		next := pagination.nextPageURL(body, pageURL, page+1)
+		if next == "" || visited[next] {
+			return pages, nil
+		}
+		visited[next] = true
+
+		if pagination.DelayMS > 0 {
+			timer := time.NewTimer(time.Duration(pagination.DelayMS) * time.Millisecond)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		nextResp, err := source.Do(ctx, config.Request(next))
+		if err != nil {
+			if isPaginationEnd(err) {
+				return pages, nil
+			}
+			return nil, fmt.Errorf("第 %d 页抓取失败: %w", page+1, err)
+		}
+		pageURL, resp, body = next, nextResp, nextResp.Body
+	}
+}
+
+// isPaginationEnd 判断后续页的抓取错误是否表示已越过最后一页：url_template 按页码生成地址，
+// 超出实际页数的页面通常返回 404 或 410，此时保留已抓取的页面。
+func isPaginationEnd(err error) bool {
+	var statusErr *fetcher.StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	return statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone
+}
@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func paginatedPresenceSite(url, fetchConfig string) *database.Site {
+	return &database.Site{
+		Name: "paginated", URL: url, Container: "ul", Item: "li", StrategyType: "presence",
+		StrategyConfig: `{"type":"presence","identity":{"source":"source_url"},"on_first_baseline":"silent"}`,
+		FetchConfig:    fetchConfig,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+		},
+	}
+}
+
+func TestPaginationFollowsNextLinks(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		page := r.URL.Query().Get("p")
+		switch page {
+		case "":
+			_, _ = w.Write([]byte(`<ul><li><a href="/n/1">公告 1</a></li><li><a href="/n/2">公告 2</a></li></ul><a class="next" href="?p=2">下一页</a>`))
+		case "2":
+			// 公告 2 因新增条目被挤到第二页，跨页重复应被合并
+			_, _ = w.Write([]byte(`<ul><li><a href="/n/2">公告 2</a></li><li><a href="/n/3">公告 3</a></li></ul><a class="next" href="?p=3">下一页</a>`))
+		default:
+			_, _ = w.Write([]byte(`<ul><li><a href="/n/4">公告 4</a></li></ul><a class="next" href="?p=4">下一页</a>`))
+		}
+	}))
+	defer server.Close()
+
+	site := paginatedPresenceSite(server.URL+"/list", `{"pagination":{"next_selector":"a.next","max_pages":3}}`)
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	observations, err := engine.observe(context.Background())
+	if err != nil {
+		t.Fatalf("observe: %v", err)
+	}
+	var keys []string
+	for _, obs := range observations {
+		keys = append(keys, strings.TrimPrefix(obs.ItemKey, server.URL))
+	}
+	if got := strings.Join(keys, ","); got != "/n/1,/n/2,/n/3,/n/4" {
+		t.Fatalf("unexpected merged observations: %s", got)
+	}
+	if len(requested) != 3 {
+		t.Fatalf("max_pages should stop after 3 pages, requested %v", requested)
+	}
+}
+
+func TestPaginationURLTemplateAndConflictingDuplicates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page == "3" {
+			_, _ = w.Write([]byte(`<ul></ul>`))
+			return
+		}
+		// 两页出现相同链接但标题不同：身份配置不可靠，必须报错
+		_, _ = w.Write([]byte(fmt.Sprintf(`<ul><li><a href="/same">第 %s 页</a></li></ul>`, page)))
+	}))
+	defer server.Close()
+
+	site := paginatedPresenceSite(server.URL+"/list", `{"pagination":{"url_template":"`+server.URL+`/list?page={page}"}}`)
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, err := engine.observe(context.Background()); err == nil || !strings.Contains(err.Error(), "身份字段重复") {
+		t.Fatalf("expected cross-page identity conflict, got %v", err)
+	}
+}
+
+func TestPaginationURLTemplateStopsAtMissingPage(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		page := r.URL.Query().Get("page")
+		switch page {
+		case "", "1", "2", "3":
+			_, _ = w.Write([]byte(fmt.Sprintf(`<ul><li><a href="/n/%s">公告 %s</a></li></ul>`, page, page)))
+		case "4":
+			http.NotFound(w, r)
+		default:
+			t.Errorf("pagination should stop at the missing page, requested %s", r.URL.RequestURI())
+		}
+	}))
+	defer server.Close()
+
+	site := paginatedPresenceSite(server.URL+"/list?page=1", `{"pagination":{"url_template":"`+server.URL+`/list?page={page}","max_pages":5}}`)
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	observations, err := engine.observe(context.Background())
+	if err != nil {
+		t.Fatalf("a 404 after the last page should end pagination, got %v", err)
+	}
+	if len(observations) != 3 || len(requested) != 4 {
+		t.Fatalf("expected 3 pages of items after 4 requests, got %d items from %v", len(observations), requested)
+	}
+
+	// 404/410 以外的错误状态仍使检查失败
+	blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`<ul><li><a href="/n/1">公告 1</a></li></ul>`))
+	}))
+	defer blocked.Close()
+	site = paginatedPresenceSite(blocked.URL+"/list", `{"pagination":{"url_template":"`+blocked.URL+`/list?page={page}","max_pages":5}}`)
+	if engine, err = NewEngine(site); err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	if _, err := engine.observe(context.Background()); err == nil || !strings.Contains(err.Error(), "第 2 页抓取失败") {
+		t.Fatalf("expected other status errors on later pages to fail the check, got %v", err)
+	}
+}
+
+func TestPaginationConfigValidation(t *testing.T) {
+	cases := []string{
+		`{"pagination":{}}`,
+		`{"pagination":{"next_selector":"a.next","url_template":"https://example.com/?p={page}"}}`,
+		`{"pagination":{"url_template":"https://example.com/list"}}`,
+		`{"pagination":{"url_template":"/list?p={page}"}}`,
+		`{"pagination":{"next_selector":"a.next","max_pages":99}}`,
+		`{"pagination":{"next_selector":"a.next","delay_ms":-1}}`,
+	}
+	for _, fetchConfig := range cases {
+		if err := NormalizeAndValidateSiteDefinition(paginatedPresenceSite("https://example.com/list", fetchConfig)); err == nil {
+			t.Errorf("expected %s to be rejected", fetchConfig)
+		}
+	}
+
+	site := paginatedPresenceSite("https://example.com/list", `{"pagination":{"next_selector":"a.next"}}`)
+	site.ExtractMode = ExtractModeJSON
+	site.Container = "$.items[*]"
+	site.Item = ""
+	site.Fields = []database.SiteField{{Name: "title", Selector: "title", Type: "text"}}
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil {
+		t.Error("next_selector should be rejected in JSON mode")
+	}
+
+	site = paginatedPresenceSite("https://example.com/list", `{"pagination":{"next_selector":"a.next"}}`)
+	if err := NormalizeAndValidateSiteDefinition(site); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if !strings.Contains(site.FetchConfig, `"max_pages":5`) {
+		t.Fatalf("default max_pages should be canonicalized: %s", site.FetchConfig)
+	}
+}
@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cn-maul/Gentry/fetcher"
+)
+
+const (
+	defaultRetryAttempts = 3
+	maxRetryAttempts     = 6
+	defaultRetryBackoff  = time.Second
+	maxRetryBackoff      = 30 * time.Second
+	// maxRetryAfter 站点要求等待超过该时间时放弃重试，留给下一次检查
+	maxRetryAfter = time.Minute
+)
+
+// RetryConfig 单次检查内的抓取重试配置，只对超时、连接中断、429 和 5xx 生效。
+type RetryConfig struct {
+	// Attempts 每个请求最多尝试的次数（含首次），未设置时为 3，0 和 1 表示不重试，上限 6
+	Attempts *int `json:"attempts,omitempty"`
+	// BackoffMs 首次重试前的等待毫秒数，之后每次翻倍，默认 1000，上限 30000
+	BackoffMs int `json:"backoff_ms,omitempty"`
+}
+
+func (c *RetryConfig) normalize() error {
+	if c.Attempts != nil && (*c.Attempts < 0 || *c.Attempts > maxRetryAttempts) {
+		return fmt.Errorf("重试次数必须在 0-%d 之间", maxRetryAttempts)
+	}
+	if c.BackoffMs < 0 || time.Duration(c.BackoffMs)*time.Millisecond > maxRetryBackoff {
+		return fmt.Errorf("重试等待时间必须在 0-%d 毫秒之间", maxRetryBackoff.Milliseconds())
+	}
+	return nil
+}
+
+func (c *FetchConfig) retryPolicy() (attempts int, backoff time.Duration) {
+	attempts, backoff = defaultRetryAttempts, defaultRetryBackoff
+	if c.Retry != nil {
+		if c.Retry.Attempts != nil {
+			attempts = max(*c.Retry.Attempts, 1)
+		}
+		if c.Retry.BackoffMs > 0 {
+			backoff = time.Duration(c.Retry.BackoffMs) * time.Millisecond
+		}
+	}
+	return attempts, backoff
+}
+
+// retrySource 在暂时性错误时按指数退避重试，服务端给出 Retry-After 时以其为准。
+type retrySource struct {
+	source   fetcher.Source
+	attempts int
+	backoff  time.Duration
+}
+
+func (s *retrySource) Do(ctx context.Context, r fetcher.Request) (*fetcher.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	delay := s.backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := s.source.Do(ctx, r)
+		if err == nil || attempt >= s.attempts || !fetcher.Retryable(err) {
+			return resp, err
+		}
+		wait := delay
+		if retryAfter := fetcher.RetryAfter(err); retryAfter > 0 {
+			if retryAfter > maxRetryAfter {
+				return nil, err
+			}
+			wait = retryAfter
+		}
+		log.Printf("[抓取] %s 第 %d 次请求失败（%s），%v 后重试: %v", r.URL, attempt, fetcher.ClassifyError(err), wait, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxRetryBackoff)
+	}
+}
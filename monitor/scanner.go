@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -47,6 +48,9 @@ type ScanMonitorConfig struct {
 	Container string            `json:"container"`
 	Item      string            `json:"item"`
 	Fields    []ScanFieldConfig `json:"fields"`
+	// ExtractMode 为 feed 时表示订阅源候选，URL 为订阅源地址
+	ExtractMode string `json:"extract_mode,omitempty"`
+	URL         string `json:"url,omitempty"`
 }
 
 type ScanFieldConfig struct {
@@ -60,6 +64,8 @@ type ScanFieldConfig struct {
 type ScanSettings struct {
 	URL      string   `json:"url"`
 	Keywords []string `json:"keywords"`
+	// FetchConfig 扫描时使用的抓取配置（请求头、代理、TLS 等），为空时使用默认 GET 请求
+	FetchConfig *FetchConfig `json:"fetch_config,omitempty"`
 }
 
 type scanStrategyResult struct {
@@ -336,8 +342,13 @@ func smartScanHTMLWithSettings(html string, settings *ScanSettings) (*ScanResult
 		strategyResults = append(strategyResults, keywordAncestorStrategy(doc, matches)...)
 	}
 
+	// 商品页的 schema.org 结构化数据比可见标记更可靠，作为首个候选
+	result := &ScanResult{URL: settings.URL}
+	if candidate, ok := structuredDataCandidate(doc, html); ok {
+		result.Containers = append(result.Containers, candidate)
+	}
 	if len(strategyResults) == 0 {
-		return &ScanResult{URL: settings.URL}, nil
+		return result, nil
 	}
 	for _, sr := range strategyResults {
 		container := sr.container
@@ -388,7 +399,6 @@ func smartScanHTMLWithSettings(html string, settings *ScanSettings) (*ScanResult
 		containerOrder = containerOrder[:maxContainers]
 	}
 
-	result := &ScanResult{URL: settings.URL}
 	for _, key := range containerOrder {
 		entry := containerMap[key]
 		css := buildShortSelector(entry.parent, entry.key.tag)
@@ -473,12 +483,34 @@ func smartScanHTML(html string, keywords []string) (*ScanResult, error) {
 }
 
 func SmartScan(settings *ScanSettings) (*ScanResult, error) {
-	f := fetcher.New()
-	html, err := f.Fetch(settings.URL)
+	config := settings.FetchConfig
+	if config == nil {
+		config = &FetchConfig{}
+	}
+	source := config.NewSource(fetcher.New())
+	fetch := func(pageURL string) (string, error) {
+		resp, err := source.Do(context.Background(), config.Request(pageURL))
+		if err != nil {
+			return "", err
+		}
+		return resp.Body, nil
+	}
+	html, err := fetch(settings.URL)
 	if err != nil {
 		return nil, fmt.Errorf("抓取页面失败: %w", err)
 	}
-	return smartScanHTMLWithSettings(html, settings)
+	// 地址本身就是订阅源时直接返回订阅源候选
+	if candidate, err := feedCandidate(settings.URL, "", html); err == nil {
+		return &ScanResult{URL: settings.URL, Containers: []ContainerInfo{candidate}}, nil
+	}
+	result, err := smartScanHTMLWithSettings(html, settings)
+	if err != nil {
+		return nil, err
+	}
+	if feeds := scanFeedCandidates(html, settings.URL, fetch); len(feeds) > 0 {
+		result.Containers = append(feeds, result.Containers...)
+	}
+	return result, nil
 }
 
 func buildScanConfig(parent *goquery.Selection, itemCSS string, samples []ExtractResult) ScanMonitorConfig {
@@ -512,11 +544,11 @@ func inferScanFields(parent *goquery.Selection, itemCSS string, samples []Extrac
 func inferTitleSelector(parent *goquery.Selection, itemCSS string, samples []ExtractResult) string {
 	itemLower := strings.ToLower(itemCSS)
 	if strings.HasPrefix(itemLower, "li") || strings.HasPrefix(itemLower, "tr") || strings.HasPrefix(itemLower, "dd") || strings.HasPrefix(itemLower, "dt") {
-		if parent.Find(itemCSS).First().Find("a").Length() == 0 {
+		if FindSelection(parent, itemCSS).First().Find("a").Length() == 0 {
 			return ""
 		}
 	}
-	item := parent.Find(itemCSS).First()
+	item := FindSelection(parent, itemCSS).First()
 	if item.Length() == 0 {
 		return "a"
 	}
@@ -539,7 +571,7 @@ func inferTitleSelector(parent *goquery.Selection, itemCSS string, samples []Ext
 }
 
 func inferURLSelector(parent *goquery.Selection, itemCSS, titleSelector string) string {
-	item := parent.Find(itemCSS).First()
+	item := FindSelection(parent, itemCSS).First()
 	if item.Length() == 0 {
 		return "a"
 	}
@@ -560,7 +592,7 @@ func inferURLSelector(parent *goquery.Selection, itemCSS, titleSelector string)
 }
 
 func inferDateSelector(parent *goquery.Selection, itemCSS string, samples []ExtractResult) string {
-	item := parent.Find(itemCSS).First()
+	item := FindSelection(parent, itemCSS).First()
 	if item.Length() == 0 {
 		return ""
 	}
@@ -582,7 +614,7 @@ func inferDateSelector(parent *goquery.Selection, itemCSS string, samples []Extr
 }
 
 func inferSummarySelector(parent *goquery.Selection, itemCSS, titleSelector string) string {
-	item := parent.Find(itemCSS).First()
+	item := FindSelection(parent, itemCSS).First()
 	if item.Length() == 0 {
 		return ""
 	}
@@ -626,7 +658,7 @@ func ScanConfigToSelectors(config ScanMonitorConfig) SiteSelectors {
 	for _, f := range config.Fields {
 		fields = append(fields, FieldConfig{Name: f.Name, Selector: f.Selector, Type: f.Type, Attr: f.Attr, Transform: f.Transform})
 	}
-	return SiteSelectors{Container: config.Container, Item: config.Item, Fields: fields}
+	return SiteSelectors{Mode: config.ExtractMode, Container: config.Container, Item: config.Item, Fields: fields}
 }
 
 func scanConfigToSelectors(config ScanMonitorConfig) SiteSelectors {
@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxFeedCandidates 单个页面最多探测的订阅源数量
+const maxFeedCandidates = 3
+
+type feedLink struct {
+	URL   string
+	Title string
+}
+
+// discoverFeedLinks 提取页面通过 <link rel="alternate"> 声明的 RSS/Atom 地址。
+func discoverFeedLinks(html, pageURL string) []feedLink {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	var links []feedLink
+	seen := map[string]bool{}
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		rel := strings.ToLower(s.AttrOr("rel", ""))
+		linkType := strings.ToLower(strings.TrimSpace(s.AttrOr("type", "")))
+		if !strings.Contains(rel, "alternate") || (linkType != "application/rss+xml" && linkType != "application/atom+xml") {
+			return
+		}
+		ref, err := url.Parse(strings.TrimSpace(s.AttrOr("href", "")))
+		if err != nil {
+			return
+		}
+		resolved := base.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			return
+		}
+		feedURL := resolved.String()
+		if seen[feedURL] || len(links) >= maxFeedCandidates {
+			return
+		}
+		seen[feedURL] = true
+		links = append(links, feedLink{URL: feedURL, Title: strings.TrimSpace(s.AttrOr("title", ""))})
+	})
+	return links
+}
+
+// feedScanConfig 订阅源候选的监控配置，使用全部标准字段。
+func feedScanConfig(feedURL string) ScanMonitorConfig {
+	fields := make([]ScanFieldConfig, 0, len(feedStandardFields))
+	for _, name := range feedStandardFields {
+		fields = append(fields, ScanFieldConfig{Name: name, Type: "text"})
+	}
+	return ScanMonitorConfig{ExtractMode: ExtractModeFeed, URL: feedURL, Fields: fields}
+}
+
+// feedCandidate 将订阅源内容转换为扫描候选；内容不是订阅源时返回错误。
+func feedCandidate(feedURL, title, body string) (ContainerInfo, error) {
+	config := feedScanConfig(feedURL)
+	items, err := NewExtractor(ScanConfigToSelectors(config)).Extract(body)
+	if err != nil {
+		return ContainerInfo{}, err
+	}
+	if err := ResolveExtractedURLs(feedURL, items); err != nil {
+		return ContainerInfo{}, fmt.Errorf("链接解析失败: %w", err)
+	}
+	diagnostics := []string{"发现订阅源 " + feedURL}
+	if title != "" {
+		diagnostics[0] = fmt.Sprintf("发现订阅源「%s」%s", title, feedURL)
+	}
+	diagnostics = append(diagnostics, fmt.Sprintf("提取到 %d 个样本项", len(items)))
+	samples := items
+	if len(samples) > 10 {
+		samples = samples[:10]
+	}
+	if samples == nil {
+		samples = []ExtractResult{}
+	}
+	return ContainerInfo{
+		ContainerTag: ExtractModeFeed,
+		ItemCount:    len(items),
+		SampleItems:  samples,
+		Config:       config,
+		Strategy:     "feed",
+		Confidence:   95,
+		Diagnostics:  diagnostics,
+	}, nil
+}
+
+// scanFeedCandidates 抓取页面声明的订阅源并生成候选，失败的订阅源直接跳过。
+func scanFeedCandidates(html, pageURL string, fetch func(string) (string, error)) []ContainerInfo {
+	var candidates []ContainerInfo
+	for _, link := range discoverFeedLinks(html, pageURL) {
+		body, err := fetch(link.URL)
+		if err != nil {
+			continue
+		}
+		candidate, err := feedCandidate(link.URL, link.Title, body)
+		if err != nil || candidate.ItemCount == 0 {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
@@ -66,12 +66,12 @@ func buildSelectorRuleStrategyResult(sourceLabel, name, urlPattern, containerSel
 		name:       name,
 		urlPattern: urlPattern,
 		build: func(doc *goquery.Document, settings *ScanSettings) []scanStrategyResult {
-			container := doc.Find(containerSelector).First()
+			container := FindSelection(doc.Selection, containerSelector).First()
 			if container.Length() == 0 {
 				return nil
 			}
 			var items []ExtractResult
-			container.Find(itemSelector).Each(func(_ int, item *goquery.Selection) {
+			FindSelection(container, itemSelector).Each(func(_ int, item *goquery.Selection) {
 				text := strings.TrimSpace(item.Text())
 				if text == "" {
 					return
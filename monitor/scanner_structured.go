@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// structuredFieldPaths 结构化数据候选尝试读取的字段，每个字段取第一个有值的路径
+var structuredFieldPaths = []struct {
+	name  string
+	paths []string
+}{
+	{"title", []string{"name"}},
+	{"price", []string{"offers..price", "offers..lowPrice", "price", "lowPrice"}},
+	{"currency", []string{"offers..priceCurrency", "priceCurrency"}},
+	{"availability", []string{"offers..availability", "availability"}},
+	{"sku", []string{"sku", "productID"}},
+}
+
+// structuredDataCandidate 页面包含 schema.org Product 或 Offer 数据（JSON-LD 或 microdata）时，
+// 生成以 jsonld 字段读取价格、库存等信息的候选。只保留在页面上读到值的字段。
+func structuredDataCandidate(doc *goquery.Document, html string) (ContainerInfo, bool) {
+	nodes := schemaNodesIn(doc.Selection)
+	for _, schemaType := range []string{"Product", "Offer"} {
+		if len(filterSchemaType(nodes, schemaType)) == 0 {
+			continue
+		}
+		var fields []ScanFieldConfig
+		for _, field := range structuredFieldPaths {
+			for _, path := range field.paths {
+				candidate := ScanFieldConfig{Name: field.name, Selector: path, Type: FieldTypeJSONLD, Attr: schemaType}
+				items, err := NewExtractor(ScanConfigToSelectors(ScanMonitorConfig{Container: "html", Fields: []ScanFieldConfig{candidate}})).Extract(html)
+				if err == nil && len(items) > 0 && strings.TrimSpace(toString(items[0][field.name])) != "" {
+					fields = append(fields, candidate)
+					break
+				}
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		config := ScanMonitorConfig{Container: "html", Fields: fields}
+		items, err := NewExtractor(ScanConfigToSelectors(config)).Extract(html)
+		if err != nil || len(items) == 0 {
+			continue
+		}
+		return ContainerInfo{
+			Selector:     "html",
+			ContainerTag: "html",
+			ContainerCSS: "html",
+			ItemCount:    len(items),
+			SampleItems:  items,
+			Config:       config,
+			Strategy:     "structured_data",
+			Confidence:   90,
+			Diagnostics: []string{
+				fmt.Sprintf("发现 schema.org %s 结构化数据", schemaType),
+				fmt.Sprintf("可读取字段: %s", structuredFieldNames(fields)),
+			},
+		}, true
+	}
+	return ContainerInfo{}, false
+}
+
+func structuredFieldNames(fields []ScanFieldConfig) string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+	return strings.Join(names, "、")
+}
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -53,8 +54,57 @@ func TestExtractorTitleFallsBackToItemText(t *testing.T) {
 	}
 }
 
+func TestExtractorListHTMLAndCountFields(t *testing.T) {
+	html := `<ul class="goods"><li><a href="/p/1">商品 A</a><span class="tag">新品</span><span class="tag"> 包邮 </span><span class="tag"></span><i class="badge">现货</i><i class="badge">仓库 2</i><p class="desc"> <b>限时</b> 特价 </p></li><li><a href="/p/2">商品 B</a></li></ul>`
+	ex := NewExtractor(SiteSelectors{
+		Container: ".goods",
+		Item:      "li",
+		Fields: []FieldConfig{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "tags", Selector: ".tag", Type: FieldTypeList, Transform: "upper"},
+			{Name: "links", Selector: "a", Type: FieldTypeList, Attr: "href"},
+			{Name: "desc", Selector: ".desc", Type: FieldTypeHTML},
+			{Name: "badges", Selector: ".badge", Type: FieldTypeCount},
+			{Name: "xbadges", Selector: "xpath:count(.//i)", Type: FieldTypeCount},
+		},
+	})
+	items, err := ex.Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if got := items[0]["tags"]; !reflect.DeepEqual(got, []string{"新品", "包邮"}) {
+		t.Errorf("tags = %#v", got)
+	}
+	if got := items[0]["links"]; !reflect.DeepEqual(got, []string{"/p/1"}) {
+		t.Errorf("links = %#v", got)
+	}
+	if got := items[0]["desc"]; got != "<b>限时</b> 特价" {
+		t.Errorf("desc = %#v", got)
+	}
+	if items[0]["badges"] != "2" || items[0]["xbadges"] != "2" {
+		t.Errorf("badges = %#v, xbadges = %#v", items[0]["badges"], items[0]["xbadges"])
+	}
+	// 没有匹配时 list 和 html 字段缺失，count 为 0
+	if _, ok := items[1]["tags"]; ok {
+		t.Errorf("unexpected tags on second item: %#v", items[1]["tags"])
+	}
+	if _, ok := items[1]["desc"]; ok {
+		t.Errorf("unexpected desc on second item: %#v", items[1]["desc"])
+	}
+	if items[1]["badges"] != "0" {
+		t.Errorf("second item badges = %#v", items[1]["badges"])
+	}
+}
+
 func TestApplyTransformRegexp(t *testing.T) {
-	got := applyTransform("2026/07/07 公告", `regexp("/","-")`)
+	pipeline, err := compileTransform(`regexp("/","-")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := pipeline.apply("2026/07/07 公告")
 	if got != "2026-07-07 公告" {
 		t.Fatalf("unexpected regexp transform: %q", got)
 	}
@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// XPathPrefix 带此前缀的 HTML 选择器按 XPath 求值，其余按 CSS 选择器处理。
+const XPathPrefix = "xpath:"
+
+// htmlSelector 编译后的 HTML 选择器，CSS 与 XPath 共用同一套查找接口。
+type htmlSelector struct {
+	css   string
+	xpath *xpath.Expr
+}
+
+// IsXPathSelector 报告选择器是否为 XPath 表达式。
+func IsXPathSelector(expr string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expr), XPathPrefix)
+}
+
+// ValidateSelector 校验 HTML 选择器；XPath 表达式需能编译，CSS 选择器保持原有宽松处理。
+func ValidateSelector(expr string) error {
+	_, err := compileHTMLSelector(expr)
+	return err
+}
+
+func compileHTMLSelector(expr string) (htmlSelector, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, XPathPrefix) {
+		return htmlSelector{css: expr}, nil
+	}
+	source := strings.TrimSpace(strings.TrimPrefix(expr, XPathPrefix))
+	if source == "" {
+		return htmlSelector{}, fmt.Errorf("XPath 表达式不能为空")
+	}
+	compiled, err := xpath.Compile(source)
+	if err != nil {
+		return htmlSelector{}, fmt.Errorf("XPath 表达式 %q 无效: %w", source, err)
+	}
+	return htmlSelector{xpath: compiled}, nil
+}
+
+func (s htmlSelector) empty() bool {
+	return s.xpath == nil && s.css == ""
+}
+
+// find 在每个当前节点上求值选择器。CSS 只查找后代；XPath 以当前节点为上下文，
+// 可以选中兄弟节点（如 following-sibling::td）或文本节点。
+func (s htmlSelector) find(sel *goquery.Selection) *goquery.Selection {
+	if s.xpath == nil {
+		return sel.Find(s.css)
+	}
+	var nodes []*html.Node
+	for _, node := range sel.Nodes {
+		if iter, ok := s.xpath.Evaluate(navigatorAt(node)).(*xpath.NodeIterator); ok {
+			for iter.MoveNext() {
+				current := iter.Current()
+				// 属性节点（如 ./a/@href）转为独立文本节点，按文本读取即为属性值
+				if current.NodeType() == xpath.AttributeNode {
+					nodes = append(nodes, &html.Node{Type: html.TextNode, Data: current.Value()})
+					continue
+				}
+				nodes = append(nodes, current.(*htmlquery.NodeNavigator).Current())
+			}
+		}
+	}
+	// Slice 的结果与 sel 共用底层数组，清空后再追加，避免覆盖调用方的节点
+	result := sel.Slice(0, 0)
+	result.Nodes = nil
+	return result.AddNodes(nodes...)
+}
+
+// scalar 对返回字符串、数值或布尔值的 XPath 表达式（如 normalize-space(...)）直接取结果，
+// 表达式返回节点集时 ok 为 false。
+func (s htmlSelector) scalar(sel *goquery.Selection) (value string, ok bool) {
+	if s.xpath == nil || sel.Length() == 0 {
+		return "", false
+	}
+	switch result := s.xpath.Evaluate(navigatorAt(sel.Get(0))).(type) {
+	case string:
+		return result, true
+	case float64:
+		return strconv.FormatFloat(result, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(result), true
+	}
+	return "", false
+}
+
+// navigatorAt 返回定位在 node 上、根为整个文档的导航器，
+// 使 // 开头的表达式按文档求值，. 开头的表达式相对当前节点求值，与 XPath 的常规语义一致。
+func navigatorAt(node *html.Node) *htmlquery.NodeNavigator {
+	root := node
+	var path []*html.Node
+	for root.Parent != nil {
+		path = append(path, root)
+		root = root.Parent
+	}
+	nav := htmlquery.CreateXPathNavigator(root)
+	for i := len(path) - 1; i >= 0; i-- {
+		nav.MoveToChild()
+		for nav.Current() != path[i] && nav.MoveToNext() {
+		}
+	}
+	return nav
+}
+
+// FindSelection 在 sel 中查找 CSS 或 XPath 选择器匹配的节点，选择器无效时返回空结果。
+func FindSelection(sel *goquery.Selection, expr string) *goquery.Selection {
+	selector, err := compileHTMLSelector(expr)
+	if err != nil {
+		return sel.Slice(0, 0)
+	}
+	return selector.find(sel)
+}
@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func TestXPathSelectorsExtractAlongsideCSS(t *testing.T) {
+	page := `<html><body>
+<div class="product"><table>
+  <tr><th>名称</th><td><a href="/p/1">商品 A</a></td></tr>
+  <tr><th>价格</th><td>¥199.00</td></tr>
+</table><p><span class="label">库存:</span> 12 件</p></div>
+<div class="product"><table>
+  <tr><th>名称</th><td><a href="/p/2">商品 B</a></td></tr>
+  <tr><th>价格</th><td>¥5.00</td></tr>
+</table><p><span class="label">库存:</span> 3 件</p></div>
+<a class="next" href="/page/2">下一页</a>
+</body></html>`
+
+	extractor := NewExtractor(SiteSelectors{
+		Container: "xpath://body",
+		Item:      "div.product",
+		Fields: []FieldConfig{
+			{Name: "title", Selector: "xpath:.//th[.='名称']/following-sibling::td[1]", Type: "text"},
+			{Name: "url", Selector: "xpath:.//a/@href", Type: "text"},
+			{Name: "price", Selector: "xpath:.//th[contains(., '价格')]/following-sibling::td", Type: "text"},
+			{Name: "stock", Selector: "xpath:normalize-space(.//span[@class='label']/following-sibling::text()[1])", Type: "text"},
+			{Name: "link", Selector: "td a", Type: "attr", Attr: "href"},
+		},
+	})
+	items, err := extractor.Extract(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ExtractResult{
+		{"title": "商品 A", "url": "/p/1", "price": "¥199.00", "stock": "12 件", "link": "/p/1"},
+		{"title": "商品 B", "url": "/p/2", "price": "¥5.00", "stock": "3 件", "link": "/p/2"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("expected %d items, got %v", len(want), items)
+	}
+	for i := range want {
+		for name, value := range want[i] {
+			if items[i][name] != value {
+				t.Errorf("item %d field %s = %v, want %v", i, name, items[i][name], value)
+			}
+		}
+	}
+
+	pagination := &PaginationConfig{NextSelector: "xpath://a[.='下一页']"}
+	if err := pagination.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if next := pagination.nextPageURL(page, "https://example.com/page/1", 2); next != "https://example.com/page/2" {
+		t.Fatalf("xpath next selector resolved to %q", next)
+	}
+
+	for _, mutate := range []func(*database.Site){
+		func(site *database.Site) { site.Container = "xpath://div[" },
+		func(site *database.Site) { site.Item = "xpath:" },
+		func(site *database.Site) { site.Fields[0].Selector = "xpath:following-sibling::td[" },
+		func(site *database.Site) {
+			site.Fields = append(site.Fields, database.SiteField{Name: "body", Selector: "xpath:((", Scope: FieldScopeDetail})
+		},
+	} {
+		site := &database.Site{
+			Name: "xpath", URL: "https://example.com", Container: "xpath://body", Item: "div.product",
+			Fields: []database.SiteField{{Name: "title", Selector: "xpath:.//td[1]"}, {Name: "url", Selector: "a", Type: "attr"}},
+		}
+		if err := NormalizeAndValidateSiteDefinition(site); err != nil {
+			t.Fatalf("valid xpath site rejected: %v", err)
+		}
+		mutate(site)
+		if err := NormalizeAndValidateSiteDefinition(site); err == nil || !strings.Contains(err.Error(), "XPath") {
+			t.Errorf("invalid xpath should be rejected, got %v", err)
+		}
+	}
+}
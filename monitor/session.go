@@ -0,0 +1,446 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/cn-maul/Gentry/database"
+	"github.com/cn-maul/Gentry/fetcher"
+)
+
+const maxSessionSteps = 10
+
+var (
+	// sessionVariableRegex 匹配登录步骤中的 {{name}} 变量引用
+	sessionVariableRegex = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+	sessionNameRegex     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// SessionConfig 登录会话配置：依次执行登录步骤，Cookie 持久化后供每次检查复用，
+// 页面出现未登录标记时自动重新登录。
+type SessionConfig struct {
+	// Steps 依次执行的登录请求，如打开登录页提取 CSRF Token、提交账号密码
+	Steps []SessionStep `json:"steps"`
+	// Credentials 登录凭据，在步骤中以 {{name}} 引用；接口返回时脱敏
+	Credentials map[string]string `json:"credentials,omitempty"`
+	// LoggedOutMarker 页面正文匹配该正则时视为登录失效；被重定向到登录页同样视为失效
+	LoggedOutMarker string `json:"logged_out_marker,omitempty"`
+
+	loggedOutMarker *regexp.Regexp
+}
+
+// SessionStep 一个登录请求。URL、请求头和请求体中可以使用 {{name}} 引用凭据或前序步骤提取的变量。
+type SessionStep struct {
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// Extract 从响应中提取变量供后续步骤引用
+	Extract map[string]SessionExtract `json:"extract,omitempty"`
+}
+
+// SessionExtract 按 CSS 选择器或 XPath 提取变量，Attr 为空时取元素文本。
+type SessionExtract struct {
+	Selector string `json:"selector"`
+	Attr     string `json:"attr,omitempty"`
+}
+
+func (c *SessionConfig) normalize() error {
+	if len(c.Steps) == 0 || len(c.Steps) > maxSessionSteps {
+		return fmt.Errorf("登录步骤数量必须在 1-%d 之间", maxSessionSteps)
+	}
+	defined := make(map[string]bool, len(c.Credentials))
+	for name := range c.Credentials {
+		if !sessionNameRegex.MatchString(name) {
+			return fmt.Errorf("登录凭据名称无效: %q", name)
+		}
+		defined[name] = true
+	}
+	for i := range c.Steps {
+		step := &c.Steps[i]
+		if err := step.normalize(defined); err != nil {
+			return fmt.Errorf("登录第 %d 步: %w", i+1, err)
+		}
+		for name := range step.Extract {
+			defined[name] = true
+		}
+	}
+	c.LoggedOutMarker = strings.TrimSpace(c.LoggedOutMarker)
+	c.loggedOutMarker = nil
+	if c.LoggedOutMarker != "" {
+		marker, err := regexp.Compile(c.LoggedOutMarker)
+		if err != nil {
+			return fmt.Errorf("未登录标记不是有效的正则表达式: %w", err)
+		}
+		c.loggedOutMarker = marker
+	}
+	return nil
+}
+
+func (s *SessionStep) normalize(defined map[string]bool) error {
+	s.Method = strings.ToUpper(strings.TrimSpace(s.Method))
+	switch s.Method {
+	case "", http.MethodGet:
+		s.Method = ""
+		if s.Body != "" {
+			return fmt.Errorf("GET 请求不能配置请求体")
+		}
+	case http.MethodPost:
+	default:
+		return fmt.Errorf("登录请求方法仅支持 GET 或 POST: %s", s.Method)
+	}
+
+	s.URL = strings.TrimSpace(s.URL)
+	parsed, err := url.Parse(sessionVariableRegex.ReplaceAllString(s.URL, "x"))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("登录地址必须是 http(s) 绝对地址: %s", s.URL)
+	}
+
+	texts := []string{s.URL, s.Body}
+	for name, value := range s.Headers {
+		if !httpTokenRegex.MatchString(name) {
+			return fmt.Errorf("请求头名称无效: %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n\x00") {
+			return fmt.Errorf("请求头 %s 的值包含非法字符", name)
+		}
+		texts = append(texts, value)
+	}
+	for _, text := range texts {
+		for _, match := range sessionVariableRegex.FindAllStringSubmatch(text, -1) {
+			if !defined[match[1]] {
+				return fmt.Errorf("未定义的变量: %s", match[1])
+			}
+		}
+	}
+
+	for name, extract := range s.Extract {
+		if !sessionNameRegex.MatchString(name) {
+			return fmt.Errorf("提取变量名称无效: %q", name)
+		}
+		extract.Selector = strings.TrimSpace(extract.Selector)
+		extract.Attr = strings.TrimSpace(extract.Attr)
+		if extract.Selector == "" {
+			return fmt.Errorf("提取变量 %s 缺少选择器", name)
+		}
+		if err := ValidateSelector(extract.Selector); err != nil {
+			return fmt.Errorf("提取变量 %s: %w", name, err)
+		}
+		s.Extract[name] = extract
+	}
+	return nil
+}
+
+// fingerprint 会话配置摘要，登录步骤或凭据变化后已保存的 Cookie 作废。
+func (c *SessionConfig) fingerprint() string {
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionCookie 持久化的一条 Set-Cookie 记录及其来源地址，恢复时按原地址重放进 Cookie 容器。
+type sessionCookie struct {
+	URL       string `json:"url"`
+	SetCookie string `json:"set_cookie"`
+}
+
+// sessionJar 记录所有写入的 Cookie，以便持久化到数据库。
+type sessionJar struct {
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	entries map[string]sessionCookie
+	dirty   bool
+}
+
+func newSessionJar(saved string) *sessionJar {
+	jar, _ := cookiejar.New(nil)
+	j := &sessionJar{jar: jar, entries: make(map[string]sessionCookie)}
+	var entries []sessionCookie
+	if saved == "" || json.Unmarshal([]byte(saved), &entries) != nil {
+		return j
+	}
+	for _, entry := range entries {
+		u, err := url.Parse(entry.URL)
+		if err != nil {
+			continue
+		}
+		cookie, err := http.ParseSetCookie(entry.SetCookie)
+		if err != nil {
+			continue
+		}
+		j.record(u, cookie)
+		jar.SetCookies(u, []*http.Cookie{cookie})
+	}
+	j.dirty = false
+	return j
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, cookie := range cookies {
+		j.record(u, cookie)
+	}
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+func (j *sessionJar) record(u *url.URL, cookie *http.Cookie) {
+	domain := cookie.Domain
+	if domain == "" {
+		domain = u.Hostname()
+	}
+	key := strings.Join([]string{cookie.Name, strings.TrimPrefix(domain, "."), cookie.Path}, "|")
+	j.entries[key] = sessionCookie{URL: u.Scheme + "://" + u.Host + "/", SetCookie: cookie.String()}
+	j.dirty = true
+}
+
+func (j *sessionJar) empty() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries) == 0
+}
+
+// export 返回需要持久化的 Cookie（已过期的除外），没有变化时 changed 为 false。
+func (j *sessionJar) export() (data string, changed bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.dirty {
+		return "", false
+	}
+	j.dirty = false
+	now := time.Now()
+	entries := make([]sessionCookie, 0, len(j.entries))
+	for key, entry := range j.entries {
+		cookie, err := http.ParseSetCookie(entry.SetCookie)
+		if err != nil || cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && cookie.Expires.Before(now)) {
+			delete(j.entries, key)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	encoded, _ := json.Marshal(entries)
+	return string(encoded), true
+}
+
+// sessionSource 在来源适配器外层维护登录会话：首次使用或会话失效时执行登录步骤，
+// 所有请求共享同一个 Cookie 容器，变化的 Cookie 写回数据库。
+type sessionSource struct {
+	source   fetcher.Source
+	config   *FetchConfig
+	siteID   uint
+	siteName string
+
+	mu         sync.Mutex
+	jar        *sessionJar
+	generation int
+}
+
+// siteSource 返回站点使用的来源适配器，配置了登录会话时在外层包装会话管理。
+func (c *FetchConfig) siteSource(site *database.Site, httpFetcher *fetcher.Fetcher) fetcher.Source {
+	source := c.NewSource(httpFetcher)
+	if c.Session == nil {
+		return source
+	}
+	return &sessionSource{source: source, config: c, siteID: site.ID, siteName: site.Name}
+}
+
+func (s *sessionSource) Do(ctx context.Context, r fetcher.Request) (*fetcher.Response, error) {
+	jar, generation, err := s.ensureLoggedIn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Jar = jar
+	resp, err := s.source.Do(ctx, r)
+	s.persist(jar, time.Time{})
+	if !s.loggedOut(r.URL, resp, err) {
+		return resp, err
+	}
+	log.Printf("[%s] 登录会话已失效，重新登录", s.siteName)
+	if jar, err = s.relogin(ctx, generation); err != nil {
+		return nil, err
+	}
+	r.Jar = jar
+	resp, err = s.source.Do(ctx, r)
+	s.persist(jar, time.Time{})
+	if s.loggedOut(r.URL, resp, err) {
+		return nil, fmt.Errorf("%w: 重新登录后仍处于未登录状态", fetcher.ErrChallengePage)
+	}
+	return resp, err
+}
+
+// loggedOut 判断响应是否表明会话失效：401、被重定向到登录页或正文出现未登录标记。
+func (s *sessionSource) loggedOut(requestURL string, resp *fetcher.Response, err error) bool {
+	var statusErr *fetcher.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusUnauthorized
+	}
+	if err != nil || resp == nil {
+		return false
+	}
+	if redirectedToLogin(requestURL, resp) {
+		return true
+	}
+	marker := s.config.Session.loggedOutMarker
+	return marker != nil && marker.MatchString(resp.Body)
+}
+
+// ensureLoggedIn 载入已保存的会话，没有可用 Cookie 时执行登录，返回当前 Cookie 容器和会话代数。
+func (s *sessionSource) ensureLoggedIn(ctx context.Context) (*sessionJar, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jar == nil {
+		saved, err := database.LoadSiteSession(s.siteID)
+		if err != nil {
+			return nil, 0, err
+		}
+		cookies := ""
+		if saved != nil && saved.Fingerprint == s.config.Session.fingerprint() {
+			cookies = saved.Cookies
+		}
+		s.jar = newSessionJar(cookies)
+	}
+	if s.jar.empty() {
+		if err := s.login(ctx); err != nil {
+			return nil, 0, err
+		}
+	}
+	return s.jar, s.generation, nil
+}
+
+// relogin 重新登录；并发请求同时发现会话失效时只登录一次。
+func (s *sessionSource) relogin(ctx context.Context, generation int) (*sessionJar, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return s.jar, nil
+	}
+	if err := s.login(ctx); err != nil {
+		return nil, err
+	}
+	return s.jar, nil
+}
+
+// login 使用新的 Cookie 容器依次执行登录步骤，调用方需持有 s.mu。
+func (s *sessionSource) login(ctx context.Context) error {
+	session := s.config.Session
+	s.jar = newSessionJar("")
+	vars := make(map[string]string, len(session.Credentials))
+	for name, value := range session.Credentials {
+		vars[name] = value
+	}
+	for i, step := range session.Steps {
+		if err := s.runStep(ctx, step, vars); err != nil {
+			return fmt.Errorf("登录第 %d 步失败: %w", i+1, err)
+		}
+	}
+	s.generation++
+	log.Printf("[%s] 登录成功", s.siteName)
+	s.persist(s.jar, time.Now())
+	return nil
+}
+
+func (s *sessionSource) runStep(ctx context.Context, step SessionStep, vars map[string]string) error {
+	contentType := ""
+	for name, value := range step.Headers {
+		if http.CanonicalHeaderKey(name) == "Content-Type" {
+			contentType = value
+		}
+	}
+	req := s.config.Request(expandSessionVars(step.URL, vars, url.QueryEscape))
+	req.Method = step.Method
+	req.Body = expandSessionVars(step.Body, vars, sessionBodyEscaper(contentType))
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	} else {
+		req.Header = req.Header.Clone()
+	}
+	if req.Body == "" {
+		req.Header.Del("Content-Type")
+	} else if contentType == "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for name, value := range step.Headers {
+		req.Header.Set(name, expandSessionVars(value, vars, nil))
+	}
+	req.Jar = s.jar
+
+	resp, err := s.source.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	if len(step.Extract) == 0 {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(resp.Body))
+	if err != nil {
+		return fmt.Errorf("解析登录页面失败: %w", err)
+	}
+	for name, extract := range step.Extract {
+		sel := FindSelection(doc.Selection, extract.Selector).First()
+		if sel.Length() == 0 {
+			return fmt.Errorf("未找到变量 %s（%s）", name, extract.Selector)
+		}
+		if extract.Attr != "" {
+			vars[name] = strings.TrimSpace(sel.AttrOr(extract.Attr, ""))
+		} else {
+			vars[name] = strings.TrimSpace(sel.Text())
+		}
+	}
+	return nil
+}
+
+// persist 把变化的 Cookie 写回数据库，失败只记录日志。
+func (s *sessionSource) persist(jar *sessionJar, loggedInAt time.Time) {
+	data, changed := jar.export()
+	if !changed && loggedInAt.IsZero() {
+		return
+	}
+	if !changed {
+		data = "[]"
+	}
+	if err := database.SaveSiteSession(s.siteID, s.config.Session.fingerprint(), data, loggedInAt); err != nil {
+		log.Printf("[%s] %v", s.siteName, err)
+	}
+}
+
+// sessionBodyEscaper 按请求体格式转义变量值：表单使用 URL 编码，JSON 使用字符串转义。
+func sessionBodyEscaper(contentType string) func(string) string {
+	switch {
+	case contentType == "" || strings.Contains(contentType, "x-www-form-urlencoded"):
+		return url.QueryEscape
+	case strings.Contains(contentType, "json"):
+		return func(value string) string {
+			data, _ := json.Marshal(value)
+			return string(data[1 : len(data)-1])
+		}
+	}
+	return nil
+}
+
+func expandSessionVars(text string, vars map[string]string, escape func(string) string) string {
+	return sessionVariableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		value := vars[sessionVariableRegex.FindStringSubmatch(match)[1]]
+		if escape != nil {
+			return escape(value)
+		}
+		return value
+	})
+}
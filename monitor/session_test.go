@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func TestSessionLogsInReusesCookiesAndReloginsWhenLoggedOut(t *testing.T) {
+	setupMonitorPersistenceDB(t)
+	var (
+		mu       sync.Mutex
+		logins   int
+		sessions = map[string]bool{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/login" && r.Method == http.MethodGet:
+			http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "token-1", Path: "/"})
+			w.Write([]byte(`<form method="post"><input name="_csrf" value="token-1"><input name="user"></form>`))
+		case r.URL.Path == "/login":
+			csrf, err := r.Cookie("csrf")
+			if err != nil || csrf.Value != r.FormValue("_csrf") || r.FormValue("user") != "alice" || r.FormValue("pass") != "p&ss word" {
+				http.Error(w, "bad login", http.StatusForbidden)
+				return
+			}
+			logins++
+			sid := fmt.Sprintf("sid-%d", logins)
+			sessions[sid] = true
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: sid, Path: "/", MaxAge: 3600})
+			http.Redirect(w, r, "/", http.StatusFound)
+		default:
+			sid, err := r.Cookie("sid")
+			if err != nil || !sessions[sid.Value] {
+				w.Write([]byte(`<div class="login-tip">请登录后查看</div>`))
+				return
+			}
+			w.Write([]byte(`<ul><li><a href="/a">会员公告 A</a></li></ul>`))
+		}
+	}))
+	defer server.Close()
+
+	fetchConfig := fmt.Sprintf(`{"retry":{"attempts":1},"session":{
+		"credentials":{"user":"alice","pass":"p&ss word"},
+		"logged_out_marker":"请登录",
+		"steps":[
+			{"url":"%[1]s/login","extract":{"csrf":{"selector":"input[name=_csrf]","attr":"value"}}},
+			{"method":"POST","url":"%[1]s/login","body":"_csrf={{csrf}}&user={{user}}&pass={{ pass }}"}
+		]}}`, server.URL)
+	site := &database.Site{
+		Name: "session-site", URL: server.URL + "/members", Container: "ul", Item: "li",
+		FetchConfig: fetchConfig, ConfigVersion: 1,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href"},
+		},
+	}
+	if err := database.CreateSiteWithFields(site); err != nil {
+		t.Fatal(err)
+	}
+	m := newMonitor(site)
+	if _, err := m.CheckNow(context.Background()); err != nil {
+		t.Fatalf("first check: %v", err)
+	}
+	saved, err := database.LoadSiteSession(site.ID)
+	if err != nil || saved == nil || !strings.Contains(saved.Cookies, "sid-1") || saved.LoggedInAt.IsZero() {
+		t.Fatalf("session cookies should be persisted, got %+v (%v)", saved, err)
+	}
+
+	// 每次检查都会新建来源适配器，Cookie 应从数据库恢复而不是重新登录
+	if _, err := m.CheckNow(context.Background()); err != nil {
+		t.Fatalf("second check: %v", err)
+	}
+	mu.Lock()
+	if logins != 1 {
+		t.Fatalf("saved session should be reused, got %d logins", logins)
+	}
+	// 服务端使会话失效，下一次检查应命中未登录标记并重新登录
+	sessions = map[string]bool{}
+	mu.Unlock()
+
+	if _, err := m.CheckNow(context.Background()); err != nil {
+		t.Fatalf("check after logout: %v", err)
+	}
+	if status := m.GetStatus(); status.Blocked || status.LastError != "" {
+		t.Fatalf("relogin should recover the check, got %+v", status)
+	}
+	mu.Lock()
+	if logins != 2 {
+		t.Fatalf("expected one relogin, got %d logins", logins)
+	}
+	mu.Unlock()
+	if saved, _ := database.LoadSiteSession(site.ID); saved == nil || !strings.Contains(saved.Cookies, "sid-2") {
+		t.Fatalf("new session cookie should be persisted, got %+v", saved)
+	}
+
+	for _, config := range []string{
+		`{"session":{"steps":[]}}`,
+		`{"session":{"steps":[{"url":"https://example.com/login?u={{user}}"}]}}`,
+		`{"session":{"steps":[{"url":"/login"}]}}`,
+		`{"session":{"steps":[{"url":"https://example.com/login","body":"a=1"}]}}`,
+		`{"source":{"type":"file","path":"page.html"},"session":{"steps":[{"url":"https://example.com/login"}]}}`,
+	} {
+		if _, err := ParseFetchConfig(config); err == nil {
+			t.Errorf("invalid session config should be rejected: %s", config)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// 结构化数据字段类型：selector 为 JSONPath，相对结构化数据节点求值
+const (
+	// FieldTypeJSONLD 读取 JSON-LD 和 microdata 中的 schema.org 数据，attr 可限定 @type（如 Product）
+	FieldTypeJSONLD = "jsonld"
+	// FieldTypeScript 读取脚本中的 JSON 状态，attr 为变量名（如 window.__INITIAL_STATE__）或 script 元素的 id
+	FieldTypeScript = "script"
+)
+
+// isStructuredFieldType 报告字段类型是否从结构化数据读取。
+func isStructuredFieldType(fieldType string) bool {
+	return fieldType == FieldTypeJSONLD || fieldType == FieldTypeScript
+}
+
+// ValidateHTMLField 校验 HTML 页面上的字段：结构化数据字段的选择器是 JSONPath，其余为 CSS 或 XPath。
+func ValidateHTMLField(field FieldConfig) error {
+	if !isStructuredFieldType(field.Type) {
+		return ValidateSelector(field.Selector)
+	}
+	if field.Type == FieldTypeScript && strings.TrimSpace(field.Attr) == "" {
+		return fmt.Errorf("script 类型需要在 attr 中填写变量名或 script 元素 id")
+	}
+	_, err := compileJSONPath(field.Selector)
+	return err
+}
+
+// structuredData 缓存一次提取中整页的结构化数据。条目内没有结构化数据时回退到整页，
+// 使列表中每个条目可以读取自己的 microdata，单商品页也能读取 <head> 中的 JSON-LD。
+type structuredData struct {
+	doc         *goquery.Selection
+	schemaNodes []interface{}
+	schemaReady bool
+	states      map[string]interface{}
+}
+
+func newStructuredData(doc *goquery.Selection) *structuredData {
+	return &structuredData{doc: doc, states: make(map[string]interface{})}
+}
+
+// value 按字段类型读取条目或整页结构化数据中 path 指向的第一个标量值。
+func (d *structuredData) value(item *goquery.Selection, path jsonPath, field FieldConfig) (string, bool) {
+	var roots []interface{}
+	switch field.Type {
+	case FieldTypeJSONLD:
+		nodes := schemaNodesIn(item)
+		if len(nodes) == 0 {
+			if !d.schemaReady {
+				d.schemaNodes, d.schemaReady = schemaNodesIn(d.doc), true
+			}
+			nodes = d.schemaNodes
+		}
+		roots = filterSchemaType(nodes, strings.TrimSpace(field.Attr))
+	case FieldTypeScript:
+		name := strings.TrimSpace(field.Attr)
+		state, ok := scriptStateIn(item, name)
+		if !ok {
+			cached, seen := d.states[name]
+			if !seen {
+				cached, _ = scriptStateIn(d.doc, name)
+				d.states[name] = cached
+			}
+			state, ok = cached, cached != nil
+		}
+		if ok {
+			roots = []interface{}{state}
+		}
+	}
+	for _, root := range roots {
+		for _, node := range path.eval(root) {
+			if value, ok := jsonScalarText(node); ok {
+				return value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// schemaNodesIn 收集 sel 内（含自身）的 JSON-LD 节点和顶层 microdata 条目；
+// JSON-LD 的顶层数组和 @graph 展开为多个节点。
+func schemaNodesIn(sel *goquery.Selection) []interface{} {
+	var nodes []interface{}
+	withSelf(sel, `script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		decoder := json.NewDecoder(strings.NewReader(s.Text()))
+		decoder.UseNumber()
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			return
+		}
+		nodes = append(nodes, flattenJSONLD(doc)...)
+	})
+	withSelf(sel, "[itemscope]").Each(func(_ int, s *goquery.Selection) {
+		if _, nested := s.Attr("itemprop"); !nested {
+			nodes = append(nodes, microdataItem(s))
+		}
+	})
+	return nodes
+}
+
+// withSelf 在 sel 的后代中查找 selector，sel 自身匹配时一并返回。
+func withSelf(sel *goquery.Selection, selector string) *goquery.Selection {
+	return sel.Filter(selector).AddSelection(sel.Find(selector))
+}
+
+func flattenJSONLD(doc interface{}) []interface{} {
+	switch value := doc.(type) {
+	case []interface{}:
+		var nodes []interface{}
+		for _, child := range value {
+			nodes = append(nodes, flattenJSONLD(child)...)
+		}
+		return nodes
+	case map[string]interface{}:
+		if graph, ok := value["@graph"].([]interface{}); ok {
+			return flattenJSONLD(graph)
+		}
+		return []interface{}{value}
+	}
+	return nil
+}
+
+// filterSchemaType 返回 @type 匹配的节点，包括嵌套的节点（如 WebPage.mainEntity 中的 Product）；
+// schemaType 为空时原样返回。
+func filterSchemaType(nodes []interface{}, schemaType string) []interface{} {
+	if schemaType == "" {
+		return nodes
+	}
+	var matched []interface{}
+	for _, node := range nodes {
+		for _, candidate := range jsonDescendants(node, nil) {
+			if object, ok := candidate.(map[string]interface{}); ok && hasSchemaType(object, schemaType) {
+				matched = append(matched, object)
+			}
+		}
+	}
+	return matched
+}
+
+// hasSchemaType 比较 @type 的最后一段，Product、schema:Product 和 https://schema.org/Product 视为相同。
+func hasSchemaType(node map[string]interface{}, schemaType string) bool {
+	types := []interface{}{node["@type"]}
+	if list, ok := node["@type"].([]interface{}); ok {
+		types = list
+	}
+	for _, t := range types {
+		if name, ok := t.(string); ok && strings.EqualFold(schemaTypeName(name), schemaTypeName(schemaType)) {
+			return true
+		}
+	}
+	return false
+}
+
+func schemaTypeName(name string) string {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndexAny(name, "/:#"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// microdataItem 把 itemscope 元素转换为与 JSON-LD 相同结构的对象，
+// 同名属性出现多次时转为数组，嵌套 itemscope 转为子对象。
+func microdataItem(scope *goquery.Selection) map[string]interface{} {
+	item := make(map[string]interface{})
+	if itemType := strings.Fields(scope.AttrOr("itemtype", "")); len(itemType) > 0 {
+		item["@type"] = schemaTypeName(itemType[0])
+	}
+	var collect func(parent *goquery.Selection)
+	collect = func(parent *goquery.Selection) {
+		parent.Children().Each(func(_ int, child *goquery.Selection) {
+			_, isScope := child.Attr("itemscope")
+			if props, ok := child.Attr("itemprop"); ok {
+				var value interface{}
+				if isScope {
+					value = microdataItem(child)
+				} else {
+					value = microdataValue(child)
+				}
+				for _, name := range strings.Fields(props) {
+					switch existing := item[name].(type) {
+					case nil:
+						item[name] = value
+					case []interface{}:
+						item[name] = append(existing, value)
+					default:
+						item[name] = []interface{}{existing, value}
+					}
+				}
+			}
+			if !isScope {
+				collect(child)
+			}
+		})
+	}
+	collect(scope)
+	return item
+}
+
+func microdataValue(sel *goquery.Selection) string {
+	if content, ok := sel.Attr("content"); ok {
+		return strings.TrimSpace(content)
+	}
+	attr := ""
+	switch goquery.NodeName(sel) {
+	case "a", "area", "link":
+		attr = "href"
+	case "img", "audio", "video", "source", "iframe", "embed", "track":
+		attr = "src"
+	case "object":
+		attr = "data"
+	case "time":
+		attr = "datetime"
+	case "data", "meter":
+		attr = "value"
+	}
+	if value, ok := sel.Attr(attr); ok {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(sel.Text())
+}
+
+// scriptStateIn 在 sel 内（含自身）的脚本中查找 JSON 状态：name 为 script 元素 id 时解析整个脚本，
+// 否则查找 name = {...} 或 name = JSON.parse("...") 形式的赋值。
+func scriptStateIn(sel *goquery.Selection, name string) (interface{}, bool) {
+	if name == "" {
+		return nil, false
+	}
+	var state interface{}
+	found := false
+	withSelf(sel, "script").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		text := s.Text()
+		if s.AttrOr("id", "") == strings.TrimPrefix(name, "#") {
+			state, found = decodeJSONPrefix(text)
+			return !found
+		}
+		for offset := 0; ; {
+			index := strings.Index(text[offset:], name)
+			if index < 0 {
+				return true
+			}
+			start := offset + index
+			offset = start + len(name)
+			rest := strings.TrimLeft(text[offset:], " \t\r\n")
+			// 跳过 == 比较以及 name 只是更长标识符一部分的情况
+			if !strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, "==") || (start > 0 && isIdentifierByte(text[start-1])) {
+				continue
+			}
+			rest = strings.TrimLeft(rest[1:], " \t\r\n")
+			if strings.HasPrefix(rest, "JSON.parse(") {
+				var encoded string
+				if err := json.NewDecoder(strings.NewReader(rest[len("JSON.parse("):])).Decode(&encoded); err != nil {
+					continue
+				}
+				rest = encoded
+			}
+			if state, found = decodeJSONPrefix(rest); found {
+				return false
+			}
+		}
+	})
+	return state, found
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b == '$' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// decodeJSONPrefix 解析文本开头的 JSON 值，忽略其后的分号等脚本内容。
+func decodeJSONPrefix(text string) (interface{}, bool) {
+	decoder := json.NewDecoder(strings.NewReader(strings.TrimSpace(text)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || value == nil {
+		return nil, false
+	}
+	return value, true
+}
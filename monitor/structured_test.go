@@ -0,0 +1,140 @@
+package monitor
+
+import (
+	"testing"
+)
+
+const structuredProductHTML = `<html><head>
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+  {"@type":"BreadcrumbList","name":"面包屑"},
+  {"@type":["Product"],"name":"降噪耳机","sku":"EH-100","offers":{"@type":"Offer","price":"899.00","priceCurrency":"CNY","availability":"https://schema.org/InStock"}}
+]}</script>
+<script>window.__INITIAL_STATE__ = {"product":{"price":{"current":849},"stock":0}};</script>
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"title":"降噪耳机"}}}</script>
+</head><body><h1>降噪耳机</h1></body></html>`
+
+func TestExtractorReadsJSONLDAndScriptState(t *testing.T) {
+	ex := NewExtractor(SiteSelectors{
+		Container: "html",
+		Fields: []FieldConfig{
+			{Name: "title", Selector: "name", Type: FieldTypeJSONLD, Attr: "Product"},
+			{Name: "price", Selector: "offers..price", Type: FieldTypeJSONLD, Attr: "schema:Product"},
+			{Name: "availability", Selector: "offers.availability", Type: FieldTypeJSONLD, Attr: "Product", Transform: `split(/, -1)`},
+			{Name: "promo", Selector: "$.product.price.current", Type: FieldTypeScript, Attr: "window.__INITIAL_STATE__"},
+			{Name: "stock", Selector: "product.stock", Type: FieldTypeScript, Attr: "window.__INITIAL_STATE__"},
+			{Name: "next_title", Selector: "props.pageProps.title", Type: FieldTypeScript, Attr: "#__NEXT_DATA__"},
+		},
+	})
+	items, err := ex.Extract(structuredProductHTML)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	want := map[string]string{
+		"title":        "降噪耳机",
+		"price":        "899.00",
+		"availability": "InStock",
+		"promo":        "849",
+		"stock":        "0",
+		"next_title":   "降噪耳机",
+	}
+	for name, value := range want {
+		if got := items[0][name]; got != value {
+			t.Errorf("%s = %v, want %q", name, got, value)
+		}
+	}
+}
+
+func TestExtractorReadsMicrodataPerItem(t *testing.T) {
+	html := `<ul class="goods">
+<li itemscope itemtype="https://schema.org/Product"><a itemprop="url" href="/p/1"><span itemprop="name">商品 A</span></a>
+  <div itemprop="offers" itemscope itemtype="https://schema.org/Offer"><meta itemprop="priceCurrency" content="CNY"><span itemprop="price" content="19.90">¥19.90</span></div></li>
+<li itemscope itemtype="https://schema.org/Product"><a itemprop="url" href="/p/2"><span itemprop="name">商品 B</span></a>
+  <div itemprop="offers" itemscope itemtype="https://schema.org/Offer"><span itemprop="price">29.90</span></div></li>
+</ul>`
+	ex := NewExtractor(SiteSelectors{
+		Container: ".goods",
+		Item:      "li",
+		Fields: []FieldConfig{
+			{Name: "title", Selector: "name", Type: FieldTypeJSONLD, Attr: "Product"},
+			{Name: "url", Selector: "url", Type: FieldTypeJSONLD},
+			{Name: "price", Selector: "offers.price", Type: FieldTypeJSONLD, Attr: "Product"},
+		},
+	})
+	items, err := ex.Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[0]["title"] != "商品 A" || items[0]["url"] != "/p/1" || items[0]["price"] != "19.90" {
+		t.Fatalf("unexpected first item: %v", items[0])
+	}
+	if items[1]["title"] != "商品 B" || items[1]["price"] != "29.90" {
+		t.Fatalf("unexpected second item: %v", items[1])
+	}
+}
+
+func TestScriptStateSkipsComparisonsAndLongerIdentifiers(t *testing.T) {
+	html := `<script>if (window.__STATE__ == null) {} var my__STATE__ = {"v":1}; window.__STATE__ = JSON.parse("{\"v\":2}");</script>`
+	ex := NewExtractor(SiteSelectors{
+		Container: "html",
+		Fields:    []FieldConfig{{Name: "v", Selector: "v", Type: FieldTypeScript, Attr: "__STATE__"}},
+	})
+	items, err := ex.Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(items) != 1 || items[0]["v"] != "2" {
+		t.Fatalf("unexpected items: %v", items)
+	}
+}
+
+func TestValidateHTMLField(t *testing.T) {
+	valid := []FieldConfig{
+		{Selector: "a.title", Type: "text"},
+		{Selector: "offers..price", Type: FieldTypeJSONLD},
+		{Selector: "$.product.price", Type: FieldTypeScript, Attr: "window.__INITIAL_STATE__"},
+	}
+	for _, field := range valid {
+		if err := ValidateHTMLField(field); err != nil {
+			t.Errorf("expected %+v to be valid: %v", field, err)
+		}
+	}
+	invalid := []FieldConfig{
+		{Selector: "offers[", Type: FieldTypeJSONLD},
+		{Selector: "product.price", Type: FieldTypeScript},
+	}
+	for _, field := range invalid {
+		if err := ValidateHTMLField(field); err == nil {
+			t.Errorf("expected %+v to be rejected", field)
+		}
+	}
+}
+
+func TestSmartScanSuggestsStructuredProductFields(t *testing.T) {
+	res, err := smartScanHTML(structuredProductHTML, nil)
+	if err != nil {
+		t.Fatalf("smartScanHTML failed: %v", err)
+	}
+	if len(res.Containers) == 0 || res.Containers[0].Strategy != "structured_data" {
+		t.Fatalf("expected structured_data candidate first, got %+v", res.Containers)
+	}
+	candidate := res.Containers[0]
+	selectors := make(map[string]string)
+	for _, field := range candidate.Config.Fields {
+		if field.Type != FieldTypeJSONLD || field.Attr != "Product" {
+			t.Fatalf("unexpected field config: %+v", field)
+		}
+		selectors[field.Name] = field.Selector
+	}
+	if selectors["price"] != "offers..price" || selectors["sku"] != "sku" || selectors["availability"] != "offers..availability" {
+		t.Fatalf("unexpected suggested fields: %v", selectors)
+	}
+	if candidate.SampleItems[0]["price"] != "899.00" {
+		t.Fatalf("unexpected sample: %v", candidate.SampleItems)
+	}
+}
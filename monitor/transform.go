@@ -0,0 +1,324 @@
+package monitor
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// transformStep 转换管道中的一步。
+type transformStep struct {
+	name  string
+	args  []string
+	index int
+	re    *regexp.Regexp
+}
+
+// transformPipeline 字段转换管道，按顺序依次应用，如 regexp("\s+", " ") | trim( ) | lower。
+type transformPipeline []transformStep
+
+// transformArity 各转换支持的参数个数范围；-1 表示整个括号内容作为一个原样参数。
+var transformArity = map[string][2]int{
+	"trim":      {-1, -1}, // trim(chars)：去除两端指定字符，为空时去除空白
+	"prefix":    {-1, -1}, // prefix(text)：添加前缀
+	"suffix":    {-1, -1}, // suffix(text)：添加后缀
+	"default":   {-1, -1}, // default(text)：值为空时使用默认值
+	"regexp":    {2, 2},   // regexp(pattern, replacement)：正则替换
+	"replace":   {2, 2},   // replace(old, new)：文本替换
+	"split":     {2, 2},   // split(sep, index)：分割后取第 index 段，负数从末尾计
+	"substring": {1, 2},   // substring(start, length)：按字符截取，负数起点从末尾计
+	"lower":     {0, 0},
+	"upper":     {0, 0},
+	"halfwidth": {0, 0}, // 全角字母、数字、符号和空格转半角
+	"unescape":  {0, 0}, // 解码 HTML 实体，如 &amp; &#165;
+	"collapse":  {0, 0}, // 连续空白合并为一个空格并去除两端空白
+}
+
+// compileTransform 解析并校验转换管道，空字符串返回空管道。
+func compileTransform(expr string) (transformPipeline, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	var pipeline transformPipeline
+	parts := splitTopLevel(expr, '|')
+	for i, raw := range parts {
+		step, err := compileTransformStep(strings.TrimSpace(raw))
+		if err != nil {
+			// 兼容旧写法：旧版只支持单个转换，正则中括号不配对时 | 可能被误拆，整体按一步解析
+			if len(parts) > 1 {
+				if step, legacyErr := compileTransformStep(strings.TrimSpace(expr)); legacyErr == nil {
+					return transformPipeline{step}, nil
+				}
+			}
+			return nil, fmt.Errorf("第 %d 步 %q: %w", i+1, strings.TrimSpace(raw), err)
+		}
+		pipeline = append(pipeline, step)
+	}
+	return pipeline, nil
+}
+
+// legacyTransform 按旧版规则处理的转换步骤名，参数为完整的原始规则
+const legacyTransform = "legacy"
+
+// compileStoredTransform 编译已保存的转换规则。规则在新语法下无效时按旧版规则处理：
+// 旧版只识别单个 trim、prefix、suffix、regexp，其余写法原样返回字段值。
+func compileStoredTransform(expr string) transformPipeline {
+	pipeline, err := compileTransform(expr)
+	if err != nil {
+		return transformPipeline{{name: legacyTransform, args: []string{expr}}}
+	}
+	return pipeline
+}
+
+// warnedTransforms 已提示过的旧版转换规则，引擎每次检查都会重新创建，避免重复告警
+var warnedTransforms sync.Map
+
+// warnLegacyTransform 提示已保存站点的转换规则在新语法下无效，每个站点字段的同一规则只提示一次。
+func warnLegacyTransform(siteName, fieldName, expr string, err error) {
+	if _, loaded := warnedTransforms.LoadOrStore(siteName+"\x00"+fieldName+"\x00"+expr, struct{}{}); loaded {
+		return
+	}
+	log.Printf("[%s] 警告: 字段 %s 的转换规则 %q 无效（%v），按旧版规则处理，请编辑监控修正", siteName, fieldName, expr, err)
+}
+
+// applyLegacyTransform 旧版转换逻辑：格式无法识别或正则无效时原样返回。
+func applyLegacyTransform(value, transform string) string {
+	if value == "" || transform == "" {
+		return value
+	}
+	idx := strings.Index(transform, "(")
+	if idx < 0 || !strings.HasSuffix(transform, ")") {
+		return value
+	}
+	args := transform[idx+1 : len(transform)-1]
+	switch transform[:idx] {
+	case "trim":
+		return strings.Trim(value, args)
+	case "prefix":
+		return args + value
+	case "suffix":
+		return value + args
+	case "regexp":
+		pattern, replacement, ok := strings.Cut(args, ",")
+		if !ok {
+			return value
+		}
+		re, err := regexp.Compile(strings.Trim(strings.TrimSpace(pattern), `"'`))
+		if err != nil {
+			return value
+		}
+		return re.ReplaceAllString(value, strings.Trim(strings.TrimSpace(replacement), `"'`))
+	}
+	return value
+}
+
+// ValidateTransform 校验字段转换规则，供模板等不经过站点校验的入口使用。
+func ValidateTransform(expr string) error {
+	_, err := compileTransform(expr)
+	return err
+}
+
+func compileTransformStep(raw string) (transformStep, error) {
+	name, body := raw, ""
+	hasArgs := false
+	if open := strings.Index(raw, "("); open >= 0 {
+		if !strings.HasSuffix(raw, ")") {
+			return transformStep{}, fmt.Errorf("缺少右括号")
+		}
+		name, body, hasArgs = strings.TrimSpace(raw[:open]), raw[open+1:len(raw)-1], true
+	}
+	arity, ok := transformArity[name]
+	if !ok {
+		return transformStep{}, fmt.Errorf("不支持的转换: %s", name)
+	}
+	step := transformStep{name: name}
+	if arity[0] < 0 {
+		step.args = []string{unquoteTransformArg(body)}
+	} else if hasArgs && strings.TrimSpace(body) != "" {
+		for _, arg := range splitTopLevel(body, ',') {
+			step.args = append(step.args, unquoteTransformArg(strings.TrimSpace(arg)))
+		}
+		// 兼容旧写法：未加引号的替换文本中可以包含逗号
+		if name == "regexp" && len(step.args) > 2 {
+			step.args = []string{step.args[0], strings.Join(step.args[1:], ",")}
+		}
+		// 兼容旧写法：正则中的括号不配对（如 [(（]）时逗号被当作括号内内容，按第一个逗号拆分
+		if name == "regexp" && len(step.args) == 1 && !strings.ContainsAny(body[:1], `"'`) {
+			if pattern, replacement, found := strings.Cut(body, ","); found {
+				step.args = []string{strings.TrimSpace(pattern), strings.TrimSpace(replacement)}
+			}
+		}
+	}
+	if arity[0] >= 0 && (len(step.args) < arity[0] || len(step.args) > arity[1]) {
+		if arity[0] == arity[1] {
+			return transformStep{}, fmt.Errorf("需要 %d 个参数", arity[0])
+		}
+		return transformStep{}, fmt.Errorf("需要 %d-%d 个参数", arity[0], arity[1])
+	}
+
+	var err error
+	switch name {
+	case "regexp":
+		if step.re, err = regexp.Compile(step.args[0]); err != nil {
+			return transformStep{}, fmt.Errorf("正则表达式无效: %w", err)
+		}
+	case "replace":
+		if step.args[0] == "" {
+			return transformStep{}, fmt.Errorf("被替换的文本不能为空")
+		}
+	case "split":
+		if step.args[0] == "" {
+			return transformStep{}, fmt.Errorf("分隔符不能为空")
+		}
+		if step.index, err = strconv.Atoi(step.args[1]); err != nil {
+			return transformStep{}, fmt.Errorf("序号必须是整数: %s", step.args[1])
+		}
+	case "substring":
+		if step.index, err = strconv.Atoi(step.args[0]); err != nil {
+			return transformStep{}, fmt.Errorf("起点必须是整数: %s", step.args[0])
+		}
+		if len(step.args) == 2 {
+			if length, err := strconv.Atoi(step.args[1]); err != nil || length < 0 {
+				return transformStep{}, fmt.Errorf("长度必须是非负整数: %s", step.args[1])
+			}
+		}
+	}
+	return step, nil
+}
+
+// apply 依次应用管道中的转换。
+func (p transformPipeline) apply(value string) string {
+	for _, step := range p {
+		value = step.apply(value)
+	}
+	return value
+}
+
+func (s transformStep) apply(value string) string {
+	switch s.name {
+	case "trim":
+		if s.args[0] == "" {
+			return strings.TrimSpace(value)
+		}
+		return strings.Trim(value, s.args[0])
+	case "prefix":
+		if value == "" {
+			return value
+		}
+		return s.args[0] + value
+	case "suffix":
+		if value == "" {
+			return value
+		}
+		return value + s.args[0]
+	case "default":
+		if strings.TrimSpace(value) == "" {
+			return s.args[0]
+		}
+		return value
+	case "regexp":
+		return s.re.ReplaceAllString(value, s.args[1])
+	case "replace":
+		return strings.ReplaceAll(value, s.args[0], s.args[1])
+	case "split":
+		parts := strings.Split(value, s.args[0])
+		index := s.index
+		if index < 0 {
+			index += len(parts)
+		}
+		if index < 0 || index >= len(parts) {
+			return ""
+		}
+		return parts[index]
+	case "substring":
+		runes := []rune(value)
+		start := s.index
+		if start < 0 {
+			start = max(0, start+len(runes))
+		}
+		if start >= len(runes) {
+			return ""
+		}
+		end := len(runes)
+		if len(s.args) == 2 {
+			length, _ := strconv.Atoi(s.args[1])
+			end = min(end, start+length)
+		}
+		return string(runes[start:end])
+	case "lower":
+		return strings.ToLower(value)
+	case "upper":
+		return strings.ToUpper(value)
+	case "halfwidth":
+		return toHalfWidth(value)
+	case "unescape":
+		return html.UnescapeString(value)
+	case "collapse":
+		return strings.Join(strings.Fields(value), " ")
+	case legacyTransform:
+		return applyLegacyTransform(value, s.args[0])
+	}
+	return value
+}
+
+// toHalfWidth 把全角 ASCII 字符（U+FF01-U+FF5E）和全角空格转为半角。
+func toHalfWidth(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - 0xfee0
+		}
+		return r
+	}, value)
+}
+
+// splitTopLevel 按分隔符拆分，忽略引号和括号内的分隔符。
+func splitTopLevel(text string, sep rune) []string {
+	var (
+		parts []string
+		quote rune
+		depth int
+		start int
+	)
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == '\\' && i+1 < len(runes) && runes[i+1] == quote {
+				i++
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth = max(0, depth-1)
+		case r == sep && depth == 0:
+			parts = append(parts, string(runes[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// unquoteTransformArg 去掉参数两端成对的引号；引号内的 \" 或 \' 还原为引号，其余反斜杠原样保留，
+// 正则表达式中的 \d、\s 等无需双写。
+func unquoteTransformArg(arg string) string {
+	if len(arg) < 2 {
+		return arg
+	}
+	quote := arg[0]
+	if (quote != '"' && quote != '\'') || arg[len(arg)-1] != quote {
+		return arg
+	}
+	return strings.ReplaceAll(arg[1:len(arg)-1], `\`+string(quote), string(quote))
+}
@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cn-maul/Gentry/database"
+)
+
+func TestTransformPipelinesChainOperators(t *testing.T) {
+	cases := []struct {
+		transform string
+		input     string
+		want      string
+	}{
+		{`regexp("\s+", " ") | trim( ) | lower`, "  Hello   WORLD ", "hello world"},
+		{"trim", "  公告  ", "公告"},
+		{"trim(【】)", "【通知】", "通知"},
+		{"prefix(https://example.com)", "/a", "https://example.com/a"},
+		{"prefix(https://example.com)", "", ""},
+		{`replace("元", "") | trim`, "199 元", "199"},
+		{"split(/, 1)", "2026/07/07", "07"},
+		{"split(/, -1)", "a/b/c", "c"},
+		{"split(/, 5)", "a/b", ""},
+		{"substring(0, 4)", "2026年7月", "2026"},
+		{"substring(-2)", "第12期", "2期"},
+		{"halfwidth | upper", "ＡＢｃ　１２３！", "ABC 123!"},
+		{"unescape", "Tom &amp; Jerry &#165;5", "Tom & Jerry ¥5"},
+		{"collapse", " 多个 \n\t 空白 ", "多个 空白"},
+		{"collapse | default(暂无)", "   ", "暂无"},
+		{"default(暂无)", "有值", "有值"},
+		{`regexp(\d+, [$0]) | suffix(|)`, "第3页", "第[3]页|"},
+		{`regexp("/","-")`, "2026/07/07", "2026-07-07"},
+		{`replace(",", "") | split(".", 0)`, "1,299.00", "1299"},
+		// 旧版按第一个逗号拆分参数，括号不配对的正则仍按旧方式解析
+		{"regexp([(（].*,)", "公告（置顶）", "公告"},
+		{"regexp([)）].*,)", "公告）置顶", "公告"},
+		{"regexp([)]|x,)", "a)b|xc", "ab|c"},
+	}
+	for _, tc := range cases {
+		pipeline, err := compileTransform(tc.transform)
+		if err != nil {
+			t.Errorf("compile %q: %v", tc.transform, err)
+			continue
+		}
+		if got := pipeline.apply(tc.input); got != tc.want {
+			t.Errorf("%q applied to %q = %q, want %q", tc.transform, tc.input, got, tc.want)
+		}
+	}
+
+	for transform, message := range map[string]string{
+		"reverse":             "不支持的转换",
+		"trim | regexp([, x)": "正则表达式无效",
+		"lower(x)":            "需要 0 个参数",
+		"split(/)":            "需要 2 个参数",
+		"split(/, one)":       "序号必须是整数",
+		"substring(1, -2)":    "长度必须是非负整数",
+		"replace(\"\", x)":    "被替换的文本不能为空",
+		"prefix(x":            "缺少右括号",
+	} {
+		if _, err := compileTransform(transform); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q should fail with %q, got %v", transform, message, err)
+		}
+	}
+
+	site := &database.Site{
+		Name: "transform", URL: "https://example.com", Container: "body", Item: "li",
+		Fields: []database.SiteField{{Name: "title", Selector: "a", Transform: "collapse | substring(x)"}},
+	}
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil || !strings.Contains(err.Error(), "title 的转换规则无效") {
+		t.Fatalf("invalid transform should be rejected at definition time, got %v", err)
+	}
+
+	extractor := NewExtractor(SiteSelectors{Container: "body", Item: "li", Fields: []FieldConfig{
+		{Name: "title", Selector: "a", Type: "text", Transform: "collapse | default(无标题)"},
+	}})
+	items, err := extractor.Extract(`<body><li><a> 公告 <b>一</b> </a></li><li><a> </a></li></body>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0]["title"] != "公告 一" || items[1]["title"] != "无标题" {
+		t.Fatalf("unexpected extracted titles: %v", items)
+	}
+}
+
+func TestStoredLegacyTransformsFallBackToPassThrough(t *testing.T) {
+	// 升级前保存的规则未经校验，旧版对无法识别的写法原样返回字段值
+	site := &database.Site{
+		Name: "legacy-transform", URL: "https://example.com/list", Container: "ul", Item: "li",
+		StrategyType:   "field_changed",
+		StrategyConfig: `{"type":"field_changed","identity":{"field":"url"},"watch":{"fields":["title"]},"on_first_baseline":"silent"}`,
+		Fields: []database.SiteField{
+			{Name: "title", Selector: "a", Type: "text", Transform: "strip(【】)"},
+			{Name: "url", Selector: "a", Type: "attr", Attr: "href", Transform: "prefix(https://example.com"},
+		},
+	}
+	if err := NormalizeAndValidateSiteDefinition(site); err == nil {
+		t.Fatal("saving a definition with an invalid transform must still be rejected")
+	}
+	engine, err := NewEngine(site)
+	if err != nil {
+		t.Fatalf("stored legacy definition should still load: %v", err)
+	}
+	items, err := engine.extractor.Extract(`<ul><li><a href="/a">【公告】</a></li></ul>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0]["title"] != "【公告】" || items[0]["url"] != "/a" {
+		t.Fatalf("invalid legacy transforms should pass values through, got %v", items)
+	}
+
+	for transform, want := range map[string]string{
+		"trim(【】)":            "公告",
+		"regexp(公告, 通知)":      "【通知】",
+		"regexp([, x)":        "【公告】",
+		"suffix(!) | reverse": "【公告】",
+	} {
+		if got := compileStoredTransform(transform).apply("【公告】"); got != want {
+			t.Errorf("%q = %q, want %q", transform, got, want)
+		}
+	}
+}
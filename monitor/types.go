@@ -18,7 +18,9 @@ type TypedValue struct {
 	DataType string
 	Minor    int64
 	Currency string
-	Valid    bool
+	// Time datetime 类型解析出的时间
+	Time  time.Time
+	Valid bool
 }
 
 // ExtractionValidationSample 是配置验证时返回给前端的只读样本。
@@ -33,6 +35,10 @@ type ExtractionValidationSample struct {
 type ExtractionValidationResult struct {
 	ExtractedItems int                          `json:"extracted_items"`
 	Samples        []ExtractionValidationSample `json:"samples"`
+	// Fields 各字段的命中情况，详情页字段只统计抓取了详情页的样本条目
+	Fields []FieldHitStat `json:"fields"`
+	// DetailSkipped 为节省请求未抓取详情页的条目数
+	DetailSkipped int `json:"detail_skipped,omitempty"`
 }
 
 // Snapshot 当前状态快照（内存表示）
@@ -67,6 +73,18 @@ type ChangeEvent struct {
 	DedupeKey         string
 	DefinitionVersion int
 	OccurredAt        time.Time
+	// PublishedAt 条目的发布时间，未配置发布时间字段或无法解析时为零值
+	PublishedAt time.Time
+	// Field field_changed 事件中发生变化的字段
+	Field string
+	// Details 详情页字段，附加在通知正文中并参与关键词匹配
+	Details []EventDetail
+}
+
+// EventDetail 事件附带的详情页字段
+type EventDetail struct {
+	Name  string
+	Value string
 }
 
 // DetectionRule 检测规则配置
@@ -76,6 +94,14 @@ type DetectionRule struct {
 	Conditions      []Condition    `json:"conditions,omitempty"`
 	OnFirstBaseline string         `json:"on_first_baseline"`
 	Cooldown        int            `json:"cooldown,omitempty"`
+	// Timezone 解析 datetime 字段使用的时区，如 Asia/Shanghai，为空时使用服务器时区
+	Timezone string `json:"timezone,omitempty"`
+	// PublishedField 作为发布时间的 datetime 字段，只有一个 datetime 字段时自动选用
+	PublishedField string `json:"published_field,omitempty"`
+	// MaxAgeDays 发布时间早于 N 天前的条目不产生事件，0 表示不限制
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+	// Watch field_changed 策略监控的字段和比较选项
+	Watch *WatchConfig `json:"watch,omitempty"`
 }
 
 // IdentityConfig 身份字段配置
@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"time"
+
+	"github.com/cn-maul/Gentry/fetcher"
 )
 
 func init() {
@@ -76,7 +77,7 @@ func (b *barkNotifier) Send(title, content string) error {
 	}
 
 	url := fmt.Sprintf("%s/%s", b.server, b.key)
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := fetcher.NewClient(10 * time.Second)
 	resp, err := client.Post(url, "application/json; charset=utf-8", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("请求失败: %w", err)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"sort"
@@ -38,6 +40,8 @@ type addMonitorRequest struct {
 	StrategyType     string            `json:"strategy_type"`
 	StrategyConfig   json.RawMessage   `json:"strategy_config"`
 	FieldDataTypes   map[string]string `json:"field_data_types"`
+	FetchConfig      json.RawMessage   `json:"fetch_config"`
+	ExtractMode      string            `json:"extract_mode"`
 }
 
 type fieldRequest struct {
@@ -46,6 +50,7 @@ type fieldRequest struct {
 	Type      string `json:"type"`
 	Attr      string `json:"attr"`
 	Transform string `json:"transform"`
+	Scope     string `json:"scope,omitempty"`
 }
 
 type monitorConfigResponse struct {
@@ -64,6 +69,8 @@ type monitorConfigResponse struct {
 	StrategyType     string            `json:"strategy_type,omitempty"`
 	StrategyConfig   json.RawMessage   `json:"strategy_config,omitempty"`
 	FieldDataTypes   map[string]string `json:"field_data_types,omitempty"`
+	FetchConfig      json.RawMessage   `json:"fetch_config,omitempty"`
+	ExtractMode      string            `json:"extract_mode,omitempty"`
 	BaselineStatus   string            `json:"baseline_status,omitempty"`
 }
 
@@ -81,6 +88,7 @@ func monitorConfigFromSite(site *database.Site) monitorConfigResponse {
 			Type:      f.Type,
 			Attr:      f.Attr,
 			Transform: f.Transform,
+			Scope:     f.Scope,
 		})
 	}
 	var strategyConfig json.RawMessage
@@ -91,6 +99,10 @@ func monitorConfigFromSite(site *database.Site) monitorConfigResponse {
 	if site.FieldDataTypes != "" {
 		json.Unmarshal([]byte(site.FieldDataTypes), &fieldDataTypes)
 	}
+	var fetchConfig json.RawMessage
+	if site.FetchConfig != "" {
+		fetchConfig = maskFetchConfig(site.FetchConfig)
+	}
 	return monitorConfigResponse{
 		ID:               site.ID,
 		Name:             site.Name,
@@ -107,6 +119,8 @@ func monitorConfigFromSite(site *database.Site) monitorConfigResponse {
 		StrategyType:     site.StrategyType,
 		StrategyConfig:   strategyConfig,
 		FieldDataTypes:   fieldDataTypes,
+		FetchConfig:      fetchConfig,
+		ExtractMode:      site.ExtractMode,
 		BaselineStatus:   site.BaselineStatus,
 	}
 }
@@ -198,6 +212,8 @@ func dbSiteFromRequest(req *addMonitorRequest) (*database.Site, error) {
 		StrategyType:   strategyType,
 		StrategyConfig: strategyConfigStr,
 		FieldDataTypes: fieldDataTypesStr,
+		FetchConfig:    fetchConfigString(req.FetchConfig),
+		ExtractMode:    req.ExtractMode,
 		BaselineStatus: "pending",
 		ConfigVersion:  1,
 	}
@@ -208,6 +224,22 @@ func dbSiteFromRequest(req *addMonitorRequest) (*database.Site, error) {
 	return site, nil
 }
 
+func fetchConfigString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
+
+// previewFetchConfig 扫描预览编辑中的监控时，未传入抓取配置则沿用已保存的配置，
+// 否则把原样提交的脱敏代理、私钥和凭据还原为已保存的值，与更新监控一致。
+func previewFetchConfig(incoming, saved string) string {
+	if incoming == "" {
+		return saved
+	}
+	return restoreMaskedFetchConfig(incoming, saved)
+}
+
 func siteFieldsFromRequest(fields []fieldRequest) []database.SiteField {
 	result := make([]database.SiteField, 0, len(fields))
 	for _, f := range fields {
@@ -221,6 +253,7 @@ func siteFieldsFromRequest(fields []fieldRequest) []database.SiteField {
 			Type:      ft,
 			Attr:      f.Attr,
 			Transform: f.Transform,
+			Scope:     f.Scope,
 		})
 	}
 	return result
@@ -235,6 +268,7 @@ func siteFieldsToRequest(fields []database.SiteField) []fieldRequest {
 			Type:      f.Type,
 			Attr:      f.Attr,
 			Transform: f.Transform,
+			Scope:     f.Scope,
 		})
 	}
 	return result
@@ -418,6 +452,13 @@ func (s *WebServer) updateMonitor(c *gin.Context) {
 	candidate.StrategyType = strategyType
 	candidate.StrategyConfig = strategyConfigStr
 	candidate.FieldDataTypes = fieldDataTypesStr
+	// 未传入抓取配置和提取模式时保留原值，兼容不认识这些字段的客户端
+	if len(req.FetchConfig) > 0 {
+		candidate.FetchConfig = restoreMaskedFetchConfig(fetchConfigString(req.FetchConfig), candidate.FetchConfig)
+	}
+	if req.ExtractMode != "" {
+		candidate.ExtractMode = req.ExtractMode
+	}
 	candidate.Fields = siteFieldsFromRequest(req.Fields)
 	if err := applyNotifyAccountIDs(&candidate, req.NotifyAccountIDs); err != nil {
 		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "invalid notify_account_ids: "+err.Error()))
@@ -429,7 +470,12 @@ func (s *WebServer) updateMonitor(c *gin.Context) {
 	}
 
 	newFingerprint := computeDetectionFingerprint(candidate.URL, candidate.Container, candidate.Item, siteFieldsToRequest(candidate.Fields), candidate.StrategyType, candidate.StrategyConfig, candidate.FieldDataTypes)
-	needsBaseline := oldFingerprint != newFingerprint
+	// 旧记录可能没有提取模式，按 HTML 处理
+	oldExtractMode := originalSite.ExtractMode
+	if oldExtractMode == "" {
+		oldExtractMode = monitor.ExtractModeHTML
+	}
+	needsBaseline := oldFingerprint != newFingerprint || oldExtractMode != candidate.ExtractMode
 	if needsBaseline {
 		candidate.ConfigVersion++
 		candidate.BaselineStatus = "needs_baseline"
@@ -684,7 +730,7 @@ func (s *WebServer) getStats(c *gin.Context) {
 	legacyScope := func() *gorm.DB {
 		return db.Model(&database.UpdateRecord{}).
 			Joins("JOIN sites ON sites.id = update_records.site_id").
-			Where("COALESCE(sites.strategy_type, 'presence') <> ?", "field_transition")
+			Where("COALESCE(sites.strategy_type, 'presence') = ?", "presence")
 	}
 	var legacyTotal, eventTotal int64
 	legacyScope().Count(&legacyTotal)
@@ -715,6 +761,8 @@ func (s *WebServer) getStats(c *gin.Context) {
 	var totalAccounts int64
 	db.Model(&database.NotificationAccount{}).Count(&totalAccounts)
 
+	cacheStats := fetcher.CurrentCacheStats()
+
 	c.JSON(http.StatusOK, NewSuccessResponse(map[string]interface{}{
 		"total_monitors":     totalMonitors,
 		"running_monitors":   runningMonitors,
@@ -723,6 +771,8 @@ func (s *WebServer) getStats(c *gin.Context) {
 		"unnotified_updates": unnotifiedUpdates,
 		"pushed_today":       pushedToday,
 		"total_accounts":     totalAccounts,
+		"fetch_cache_hits":   cacheStats.Hits,
+		"fetch_cache_misses": cacheStats.Misses,
 	}))
 }
 
@@ -813,6 +863,25 @@ func scanRuleFieldsFromRequest(fields []scanRuleFieldRequest) []database.ScanRul
 	return result
 }
 
+// validateScanRuleRequest 校验模板的容器、条目和字段选择器（CSS、xpath: 前缀的 XPath 或结构化数据字段的 JSONPath）以及字段转换规则。
+func validateScanRuleRequest(req *scanRuleRequest) error {
+	if err := monitor.ValidateSelector(req.Container); err != nil {
+		return fmt.Errorf("容器选择器: %w", err)
+	}
+	if err := monitor.ValidateSelector(req.Item); err != nil {
+		return fmt.Errorf("条目选择器: %w", err)
+	}
+	for _, field := range req.Fields {
+		if err := monitor.ValidateHTMLField(monitor.FieldConfig{Selector: field.Selector, Type: field.Type, Attr: field.Attr}); err != nil {
+			return fmt.Errorf("字段 %s: %w", field.Name, err)
+		}
+		if err := monitor.ValidateTransform(field.Transform); err != nil {
+			return fmt.Errorf("字段 %s 的转换规则无效: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
 func dbScanRuleFromRequest(req *scanRuleRequest) *database.ScanRuleTemplate {
 	priority := req.Priority
 	if priority <= 0 {
@@ -850,6 +919,10 @@ func (s *WebServer) createScanRule(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "参数错误: "+err.Error()))
 		return
 	}
+	if err := validateScanRuleRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "参数错误: "+err.Error()))
+		return
+	}
 	rule := dbScanRuleFromRequest(&req)
 	if err := database.CreateScanRuleTemplate(rule); err != nil {
 		c.JSON(http.StatusConflict, NewErrorResponse(409, "创建扫描规则失败: "+err.Error()))
@@ -868,6 +941,10 @@ func (s *WebServer) updateScanRule(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "参数错误: "+err.Error()))
 		return
 	}
+	if err := validateScanRuleRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "参数错误: "+err.Error()))
+		return
+	}
 	id := c.Param("id")
 	var rule database.ScanRuleTemplate
 	if err := database.GetDB().Preload("Fields").First(&rule, id).Error; err != nil {
@@ -1146,12 +1223,86 @@ func (s *WebServer) updateNotificationSettings(c *gin.Context) {
 	c.JSON(http.StatusOK, NewSuccessResponse(nil))
 }
 
+// proxySettingsKey 全局代理设置在 system_settings 中的键
+const proxySettingsKey = "proxy_settings"
+
+func loadProxySettings() (fetcher.ProxySettings, error) {
+	var settings fetcher.ProxySettings
+	raw, ok := database.GetSetting(proxySettingsKey)
+	if !ok || raw == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return settings, fmt.Errorf("解析代理设置失败: %w", err)
+	}
+	return settings, nil
+}
+
+// LoadProxySettings 启动时从数据库载入全局代理设置。
+func LoadProxySettings() error {
+	settings, err := loadProxySettings()
+	if err != nil {
+		return err
+	}
+	return fetcher.SetProxySettings(settings)
+}
+
+func (s *WebServer) getProxySettings(c *gin.Context) {
+	settings, err := loadProxySettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessResponse(maskProxySettings(settings)))
+}
+
+func (s *WebServer) updateProxySettings(c *gin.Context) {
+	var req fetcher.ProxySettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "参数错误: "+err.Error()))
+		return
+	}
+	existing, err := loadProxySettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, err.Error()))
+		return
+	}
+	// 客户端原样提交的脱敏地址还原为已保存的真实地址
+	saved := proxySettingsURLs(existing)
+	req.Proxy = restoreMaskedProxy(req.Proxy, saved)
+	for i := range req.Pools {
+		for j, proxy := range req.Pools[i].Proxies {
+			req.Pools[i].Proxies[j] = restoreMaskedProxy(proxy, saved)
+		}
+	}
+	if err := req.Normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "代理设置无效: "+err.Error()))
+		return
+	}
+
+	data, _ := json.Marshal(req)
+	if err := database.SetSetting(proxySettingsKey, string(data)); err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "保存代理设置失败: "+err.Error()))
+		return
+	}
+	if err := fetcher.SetProxySettings(req); err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "应用代理设置失败: "+err.Error()))
+		return
+	}
+
+	log.Printf("[抓取] 代理设置已更新: 默认代理=%s 默认代理池=%s 代理池=%d",
+		fetcher.RedactProxyURL(req.Proxy), req.ProxyPool, len(req.Pools))
+	c.JSON(http.StatusOK, NewSuccessResponse(maskProxySettings(req)))
+}
+
 // ===== 智能扫描 =====
 
 func (s *WebServer) previewScan(c *gin.Context) {
 	var req struct {
-		URL      string `json:"url" binding:"required"`
-		Keywords string `json:"keywords"`
+		URL         string          `json:"url" binding:"required"`
+		Keywords    string          `json:"keywords"`
+		FetchConfig json.RawMessage `json:"fetch_config"`
+		Monitor     string          `json:"monitor"` // 编辑已有监控时传入名称，沿用其已保存的抓取配置
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "参数错误: "+err.Error()))
@@ -1161,6 +1312,20 @@ func (s *WebServer) previewScan(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "URL 无效: "+err.Error()))
 		return
 	}
+	fetchConfigText := fetchConfigString(req.FetchConfig)
+	if req.Monitor != "" {
+		var saved database.Site
+		if err := database.GetDB().Where("name = ?", req.Monitor).First(&saved).Error; err != nil {
+			c.JSON(http.StatusNotFound, NewErrorResponse(404, "monitor not found"))
+			return
+		}
+		fetchConfigText = previewFetchConfig(fetchConfigText, saved.FetchConfig)
+	}
+	fetchConfig, err := monitor.ParseFetchConfig(fetchConfigText)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "抓取配置无效: "+err.Error()))
+		return
+	}
 
 	// 解析关键词
 	var keywords []string
@@ -1171,8 +1336,9 @@ func (s *WebServer) previewScan(c *gin.Context) {
 	}
 
 	result, err := monitor.SmartScan(&monitor.ScanSettings{
-		URL:      req.URL,
-		Keywords: keywords,
+		URL:         req.URL,
+		Keywords:    keywords,
+		FetchConfig: fetchConfig,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "扫描失败: "+err.Error()))
@@ -1201,10 +1367,11 @@ func dbSiteFromScanRequest(req *smartCreateRequest) (*database.Site, error) {
 		// legacy path - build config from container_css
 		return nil, fmt.Errorf("config is required")
 	}
-	if config.Container == "" {
+	isFeed := config.ExtractMode == monitor.ExtractModeFeed
+	if !isFeed && config.Container == "" {
 		return nil, fmt.Errorf("container selector is required")
 	}
-	if config.Item == "" {
+	if !isFeed && config.Item == "" {
 		config.Item = "a"
 	}
 	hasTitle := false
@@ -1221,11 +1388,17 @@ func dbSiteFromScanRequest(req *smartCreateRequest) (*database.Site, error) {
 	if group == "" {
 		group = "默认"
 	}
+	siteURL := req.URL
+	// 订阅源候选监控订阅源地址而非原页面
+	if isFeed && config.URL != "" {
+		siteURL = config.URL
+	}
 	site := &database.Site{
 		Name:           req.Name,
-		URL:            req.URL,
+		URL:            siteURL,
 		Container:      config.Container,
 		Item:           config.Item,
+		ExtractMode:    config.ExtractMode,
 		GroupName:      group,
 		CheckInterval:  req.CheckInterval,
 		IsActive:       true,
@@ -1264,6 +1437,12 @@ func (s *WebServer) smartCreate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "URL 无效: "+err.Error()))
 		return
 	}
+	if req.Config != nil && req.Config.URL != "" {
+		if err := validateOutboundURL(req.Config.URL); err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse(400, "订阅源 URL 无效: "+err.Error()))
+			return
+		}
+	}
 
 	// legacy fallback: use container_css
 	if req.Config == nil {
@@ -1370,7 +1549,7 @@ func (s *WebServer) testScanRule(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "解析页面失败: "+err.Error()))
 		return
 	}
-	if doc.Find(rule.Container).Length() == 0 {
+	if monitor.FindSelection(doc.Selection, rule.Container).Length() == 0 {
 		c.JSON(http.StatusBadRequest, NewErrorResponse(400, fmt.Sprintf("容器选择器 %q 未匹配到元素", rule.Container)))
 		return
 	}
@@ -1594,7 +1773,15 @@ func (s *WebServer) manualCheck(c *gin.Context) {
 	}
 	outcome, err := runningMonitor.CheckNow(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "check failed: "+err.Error()))
+		errorClass := fetcher.ClassifyError(err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Code:    500,
+			Message: "check failed: " + err.Error(),
+			Data: map[string]interface{}{
+				"error_class": errorClass,
+				"blocked":     errorClass == fetcher.ErrorClassBlocked,
+			},
+		})
 		return
 	}
 	count := len(outcome.Events)
@@ -1637,28 +1824,327 @@ func (s *WebServer) validateMonitorConfig(c *gin.Context) {
 		return
 	}
 	label := "条目提取"
-	if site.StrategyType == "field_transition" {
+	switch site.StrategyType {
+	case "field_transition":
 		label = "商品身份与价格解析"
+	case "field_changed":
+		label = "条目身份与监控字段"
+	}
+	items := []map[string]interface{}{
+		{
+			"status":  "ok",
+			"label":   label,
+			"detail":  fmt.Sprintf("成功提取并验证 %d 条记录", report.ExtractedItems),
+			"samples": report.Samples,
+		},
+	}
+	if report.DetailSkipped > 0 {
+		items = append(items, map[string]interface{}{
+			"status": "ok",
+			"label":  "详情页字段",
+			"detail": fmt.Sprintf("仅抓取前 %d 条的详情页验证，其余 %d 条未抓取", report.ExtractedItems-report.DetailSkipped, report.DetailSkipped),
+		})
+	}
+	// 只列出未全部命中的字段，便于定位失效的选择器
+	for _, field := range report.Fields {
+		if field.Matched == field.Items {
+			continue
+		}
+		items = append(items, map[string]interface{}{
+			"status": "warning",
+			"label":  "字段 " + field.Name,
+			"detail": fmt.Sprintf("命中 %d/%d 条，空值 %d 条，类型解析失败 %d 条", field.Matched, field.Items, field.Empty, field.ParseFailed),
+		})
+	}
+	if fetchConfig, err := monitor.ParseFetchConfig(site.FetchConfig); err == nil && fetchConfig.TLS != nil && fetchConfig.TLS.InsecureSkipVerify {
+		items = append(items, map[string]interface{}{
+			"status": "warning",
+			"label":  "TLS 证书校验",
+			"detail": "已跳过证书校验，连接可能被中间人窃听或篡改；建议改为配置 ca_pem",
+		})
 	}
 	c.JSON(http.StatusOK, NewSuccessResponse(map[string]interface{}{
-		"valid":           true,
-		"status":          "valid",
-		"extracted_items": report.ExtractedItems,
-		"items": []map[string]interface{}{
-			{
-				"status":  "ok",
-				"label":   label,
-				"detail":  fmt.Sprintf("成功提取并验证 %d 条记录", report.ExtractedItems),
-				"samples": report.Samples,
-			},
-		},
+		"valid":            true,
+		"status":           "valid",
+		"extracted_items":  report.ExtractedItems,
+		"fields":           report.Fields,
+		"items":            items,
 		"errors":           []string{},
 		"summary":          fmt.Sprintf("配置有效，共提取 %d 条记录；本次验证未写入基线或发送通知。", report.ExtractedItems),
 		"strategy_config":  json.RawMessage(site.StrategyConfig),
 		"field_data_types": json.RawMessage(site.FieldDataTypes),
+		"fetch_config":     fetchConfigResponse(site.FetchConfig),
+		"extract_mode":     site.ExtractMode,
+	}))
+}
+
+func fetchConfigResponse(fetchConfig string) json.RawMessage {
+	if fetchConfig == "" {
+		return json.RawMessage("{}")
+	}
+	return maskFetchConfig(fetchConfig)
+}
+
+// replayRequest 存档回放时提议的提取和检测定义，未传入的部分沿用当前监控配置。
+type replayRequest struct {
+	Container      string            `json:"container"`
+	Item           string            `json:"item"`
+	ExtractMode    string            `json:"extract_mode"`
+	Fields         []fieldRequest    `json:"fields"`
+	StrategyType   string            `json:"strategy_type"`
+	StrategyConfig json.RawMessage   `json:"strategy_config"`
+	FieldDataTypes map[string]string `json:"field_data_types"`
+}
+
+// listFieldDiagnostics 返回最近各次检查的字段命中情况，用于定位失效的选择器。
+func (s *WebServer) listFieldDiagnostics(c *gin.Context) {
+	name := c.Param("name")
+	var site database.Site
+	if err := database.GetDB().Where("name = ?", name).First(&site).Error; err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse(404, "monitor not found"))
+		return
+	}
+	limit := 20
+	if rawSize := c.Query("size"); rawSize != "" {
+		parsed, err := strconv.Atoi(rawSize)
+		if err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+	records, err := monitor.ListFieldDiagnostics(site.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "failed to load diagnostics: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessResponse(records))
+}
+
+func (s *WebServer) listSelectorRepairs(c *gin.Context) {
+	name := c.Param("name")
+	var site database.Site
+	if err := database.GetDB().Where("name = ?", name).First(&site).Error; err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse(404, "monitor not found"))
+		return
+	}
+	limit := 20
+	if rawSize := c.Query("size"); rawSize != "" {
+		parsed, err := strconv.Atoi(rawSize)
+		if err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+	repairs, err := monitor.ListSelectorRepairs(site.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "failed to load repairs: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessResponse(repairs))
+}
+
+// loadSiteRepair 按监控名称和建议 ID 读取修复建议，失败时已写入响应。
+func loadSiteRepair(c *gin.Context) (*database.Site, *database.SelectorRepair, bool) {
+	var site database.Site
+	if err := database.GetDB().Preload("Fields").Where("name = ?", c.Param("name")).First(&site).Error; err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse(404, "monitor not found"))
+		return nil, nil, false
+	}
+	repairID, err := strconv.ParseUint(c.Param("repairId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "invalid repair id"))
+		return nil, nil, false
+	}
+	repair, err := database.LoadSelectorRepair(site.ID, uint(repairID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "failed to load repair: "+err.Error()))
+		return nil, nil, false
+	}
+	if repair == nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse(404, "repair not found"))
+		return nil, nil, false
+	}
+	return &site, repair, true
+}
+
+// acceptSelectorRepair 应用修复建议：与更新监控器相同，先停止在途检查再事务性替换定义，
+// 身份仍匹配的快照迁移到新版本继续作为基线。
+func (s *WebServer) acceptSelectorRepair(c *gin.Context) {
+	originalSite, repair, ok := loadSiteRepair(c)
+	if !ok {
+		return
+	}
+	originalSite.Fields = append([]database.SiteField(nil), originalSite.Fields...)
+	candidate, keepKeys, err := monitor.RepairedDefinition(*originalSite, repair)
+	if err != nil {
+		c.JSON(http.StatusConflict, NewErrorResponse(409, "cannot accept repair: "+err.Error()))
+		return
+	}
+
+	name := originalSite.Name
+	quiesceCtx, quiesceCancel := context.WithTimeout(context.Background(), 15*time.Second)
+	quiesceErr := monitor.QuiesceMonitor(name, quiesceCtx)
+	quiesceCancel()
+	if quiesceErr != nil {
+		if _, restoreErr := monitor.AtomicReplaceMonitor(originalSite, name); restoreErr != nil {
+			log.Printf("[Web] 中止修复后恢复旧监控器「%s」失败: %v", name, restoreErr)
+		}
+		c.JSON(http.StatusConflict, NewErrorResponse(409, "monitor is busy: "+quiesceErr.Error()))
+		return
+	}
+
+	if err := database.ApplySelectorRepair(&candidate, candidate.Fields, repair.ID, originalSite.ConfigVersion, keepKeys); err != nil {
+		if _, restoreErr := monitor.AtomicReplaceMonitor(originalSite, name); restoreErr != nil {
+			log.Printf("[Web] 恢复旧监控器「%s」失败: %v", name, restoreErr)
+		}
+		log.Printf("[Web] 应用修复建议失败「%s」: %v", name, err)
+		if errors.Is(err, database.ErrSiteModified) {
+			c.JSON(http.StatusConflict, NewErrorResponse(409, "cannot accept repair: "+err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "accept repair failed: "+err.Error()))
+		return
+	}
+
+	var updatedSite database.Site
+	if err := database.GetDB().Preload("Fields").First(&updatedSite, candidate.ID).Error; err != nil {
+		log.Printf("[Web] 重新加载修复后的站点失败: %v", err)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "reload repaired monitor failed: "+err.Error()))
+		return
+	}
+	if _, err := monitor.AtomicReplaceMonitor(&updatedSite, name); err != nil {
+		log.Printf("[Web] 重启监控器「%s」失败: %v", name, err)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "restart failed: "+err.Error()))
+		return
+	}
+
+	log.Printf("[Web] 接受选择器修复建议: %s #%d（保留 %d 条基线）", name, repair.ID, len(keepKeys))
+	c.JSON(http.StatusOK, NewSuccessResponse(map[string]interface{}{
+		"config_version":  updatedSite.ConfigVersion,
+		"baseline_status": updatedSite.BaselineStatus,
+		"kept_snapshots":  len(keepKeys),
+	}))
+}
+
+func (s *WebServer) dismissSelectorRepair(c *gin.Context) {
+	site, repair, ok := loadSiteRepair(c)
+	if !ok {
+		return
+	}
+	if err := database.DismissSelectorRepair(repair.ID); err != nil {
+		c.JSON(http.StatusConflict, NewErrorResponse(409, err.Error()))
+		return
+	}
+	log.Printf("[Web] 忽略选择器修复建议: %s #%d", site.Name, repair.ID)
+	c.JSON(http.StatusOK, NewSuccessResponse(nil))
+}
+
+func (s *WebServer) listPageArchives(c *gin.Context) {
+	name := c.Param("name")
+	var site database.Site
+	if err := database.GetDB().Where("name = ?", name).First(&site).Error; err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse(404, "monitor not found"))
+		return
+	}
+	limit := 50
+	if rawSize := c.Query("size"); rawSize != "" {
+		parsed, err := strconv.Atoi(rawSize)
+		if err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+	archives, err := database.ListPageArchives(site.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "failed to load archives: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessResponse(archives))
+}
+
+// loadSiteArchive 按监控名称和存档 ID 读取存档，失败时已写入响应。
+func loadSiteArchive(c *gin.Context, preloadFields bool) (*database.Site, *database.PageArchive, bool) {
+	query := database.GetDB()
+	if preloadFields {
+		query = query.Preload("Fields")
+	}
+	var site database.Site
+	if err := query.Where("name = ?", c.Param("name")).First(&site).Error; err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse(404, "monitor not found"))
+		return nil, nil, false
+	}
+	archiveID, err := strconv.ParseUint(c.Param("archiveId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "invalid archive id"))
+		return nil, nil, false
+	}
+	archive, err := database.LoadPageArchive(site.ID, uint(archiveID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, "failed to load archive: "+err.Error()))
+		return nil, nil, false
+	}
+	if archive == nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse(404, "archive not found"))
+		return nil, nil, false
+	}
+	return &site, archive, true
+}
+
+func (s *WebServer) getPageArchive(c *gin.Context) {
+	_, archive, ok := loadSiteArchive(c, false)
+	if !ok {
+		return
+	}
+	content, err := archive.Content()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(500, err.Error()))
+		return
+	}
+	// 以 JSON 字符串返回正文，避免在本站源下直接渲染第三方页面
+	c.JSON(http.StatusOK, NewSuccessResponse(map[string]interface{}{
+		"archive": archive,
+		"content": string(content),
 	}))
 }
 
+func (s *WebServer) replayPageArchive(c *gin.Context) {
+	site, archive, ok := loadSiteArchive(c, true)
+	if !ok {
+		return
+	}
+	// 请求体可以为空，此时按当前配置回放
+	var req replayRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "invalid request: "+err.Error()))
+		return
+	}
+	candidate := *site
+	candidate.Fields = append([]database.SiteField(nil), site.Fields...)
+	if req.Container != "" || req.Item != "" || len(req.Fields) > 0 {
+		candidate.Container = req.Container
+		candidate.Item = req.Item
+		candidate.Fields = siteFieldsFromRequest(req.Fields)
+	}
+	if req.ExtractMode != "" {
+		candidate.ExtractMode = req.ExtractMode
+	}
+	if req.StrategyType != "" || len(req.StrategyConfig) > 0 {
+		candidate.StrategyType = req.StrategyType
+		candidate.StrategyConfig = string(req.StrategyConfig)
+	}
+	if req.FieldDataTypes != nil {
+		data, _ := json.Marshal(req.FieldDataTypes)
+		candidate.FieldDataTypes = string(data)
+	}
+	if err := monitor.NormalizeAndValidateSiteDefinition(&candidate); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "invalid monitor config: "+err.Error()))
+		return
+	}
+	result, err := monitor.ReplayArchive(&candidate, archive)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse(400, "replay failed: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessResponse(result))
+}
+
 // computeDetectionFingerprint 计算检测语义指纹，用于判断配置变化是否需要重建基线
 func computeDetectionFingerprint(url, container, item string, fields []fieldRequest, strategyType, strategyConfig, fieldDataTypes string) string {
 	type canonicalField struct {
@@ -1667,6 +2153,7 @@ func computeDetectionFingerprint(url, container, item string, fields []fieldRequ
 		Type      string `json:"type"`
 		Attr      string `json:"attr"`
 		Transform string `json:"transform"`
+		Scope     string `json:"scope,omitempty"`
 	}
 	canonicalFields := make([]canonicalField, 0, len(fields))
 	for _, field := range fields {
@@ -1674,8 +2161,13 @@ func computeDetectionFingerprint(url, container, item string, fields []fieldRequ
 		if fieldType == "" {
 			fieldType = "text"
 		}
+		// 列表字段不写入 scope，保持旧记录的指纹不变
+		scope := strings.ToLower(strings.TrimSpace(field.Scope))
+		if scope == monitor.FieldScopeList {
+			scope = ""
+		}
 		canonicalFields = append(canonicalFields, canonicalField{
-			Name: field.Name, Selector: field.Selector, Type: fieldType, Attr: field.Attr, Transform: field.Transform,
+			Name: field.Name, Selector: field.Selector, Type: fieldType, Attr: field.Attr, Transform: field.Transform, Scope: scope,
 		})
 	}
 	sort.Slice(canonicalFields, func(i, j int) bool {
@@ -28,6 +28,8 @@ func (s *WebServer) setupRoutes() {
 		authenticated.GET("/groups", s.listGroups)
 		authenticated.GET("/settings/notifications", s.getNotificationSettings)
 		authenticated.PUT("/settings/notifications", s.updateNotificationSettings)
+		authenticated.GET("/settings/proxies", s.getProxySettings)
+		authenticated.PUT("/settings/proxies", s.updateProxySettings)
 	}
 
 	api := authenticated.Group("/v1/monitors")
@@ -50,6 +52,13 @@ func (s *WebServer) setupRoutes() {
 		api.GET("/:name/snapshots", s.getMonitorSnapshots)
 		api.POST("/:name/baseline", s.resetBaseline)
 		api.POST("/:name/check", s.manualCheck)
+		api.GET("/:name/diagnostics", s.listFieldDiagnostics)
+		api.GET("/:name/repairs", s.listSelectorRepairs)
+		api.POST("/:name/repairs/:repairId/accept", s.acceptSelectorRepair)
+		api.POST("/:name/repairs/:repairId/dismiss", s.dismissSelectorRepair)
+		api.GET("/:name/archives", s.listPageArchives)
+		api.GET("/:name/archives/:archiveId", s.getPageArchive)
+		api.POST("/:name/archives/:archiveId/replay", s.replayPageArchive)
 		api.POST("/validate", s.validateMonitorConfig)
 	}
 
@@ -2,6 +2,7 @@ package web
 
 import (
 	"crypto/subtle"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/cn-maul/Gentry/fetcher"
 	"github.com/gin-gonic/gin"
 )
 
@@ -65,7 +67,7 @@ func validateOutboundURL(raw string) error {
 func isBlockedHostname(host string) bool {
 	lower := strings.ToLower(strings.TrimSpace(host))
 	if lower == "localhost" {
-		return true
+		return isBlockedIP(netip.IPv6Loopback()) || isBlockedIP(netip.AddrFrom4([4]byte{127, 0, 0, 1}))
 	}
 	if addr, err := netip.ParseAddr(lower); err == nil {
 		return isBlockedIP(addr)
@@ -86,13 +88,9 @@ func isBlockedHostname(host string) bool {
 	return false
 }
 
+// isBlockedIP 与抓取器拨号时的拦截策略保持一致，白名单网段放行。
 func isBlockedIP(addr netip.Addr) bool {
-	return addr.IsLoopback() ||
-		addr.IsPrivate() ||
-		addr.IsLinkLocalUnicast() ||
-		addr.IsLinkLocalMulticast() ||
-		addr.IsMulticast() ||
-		addr.IsUnspecified()
+	return fetcher.IsBlockedIP(addr)
 }
 
 func maskSecret(secret string) string {
@@ -131,3 +129,149 @@ func maskSensitiveConfig(service string, config map[string]interface{}) map[stri
 	}
 	return masked
 }
+
+// maskProxyURL 把代理地址中的密码替换为固定占位值，不泄露密码的任何字符。
+func maskProxyURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); !ok {
+		return raw
+	}
+	// url.UserPassword 会转义 *，先去掉密码再在用户名后插入占位值
+	u.User = url.User(u.User.Username())
+	return strings.Replace(u.String(), "@", ":"+maskedPlaceholder+"@", 1)
+}
+
+// restoreMaskedProxy 客户端提交的代理地址密码为占位值、其余部分与已保存地址一致时，还原为已保存的真实地址。
+func restoreMaskedProxy(incoming string, existing []string) string {
+	for _, candidate := range existing {
+		if masked := maskProxyURL(candidate); masked != candidate && incoming == masked {
+			return candidate
+		}
+	}
+	return incoming
+}
+
+// maskProxySettings 返回代理密码已脱敏的全局代理设置副本。
+func maskProxySettings(settings fetcher.ProxySettings) fetcher.ProxySettings {
+	masked := fetcher.ProxySettings{Proxy: maskProxyURL(settings.Proxy), ProxyPool: settings.ProxyPool}
+	for _, pool := range settings.Pools {
+		copied := pool
+		copied.Proxies = make([]string, len(pool.Proxies))
+		for i, proxy := range pool.Proxies {
+			copied.Proxies[i] = maskProxyURL(proxy)
+		}
+		masked.Pools = append(masked.Pools, copied)
+	}
+	return masked
+}
+
+// proxySettingsURLs 列出设置中的全部代理地址，用于还原脱敏值。
+func proxySettingsURLs(settings fetcher.ProxySettings) []string {
+	urls := []string{settings.Proxy}
+	for _, pool := range settings.Pools {
+		urls = append(urls, pool.Proxies...)
+	}
+	return urls
+}
+
+// maskedPlaceholder 替代代理密码、客户端私钥和登录凭据返回给客户端的占位值；
+// 只有提交值等于占位值时才还原为已保存的值。
+const maskedPlaceholder = "******"
+
+// maskFetchConfig 返回敏感信息已脱敏的站点抓取配置：代理密码、TLS 客户端私钥和登录凭据。
+func maskFetchConfig(fetchConfig string) json.RawMessage {
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(fetchConfig), &config); err != nil {
+		return json.RawMessage(fetchConfig)
+	}
+	changed := false
+	if proxy, ok := config["proxy"].(string); ok && maskProxyURL(proxy) != proxy {
+		config["proxy"] = maskProxyURL(proxy)
+		changed = true
+	}
+	if tlsConfig, ok := config["tls"].(map[string]interface{}); ok {
+		if key, ok := tlsConfig["client_key_pem"].(string); ok && key != "" {
+			tlsConfig["client_key_pem"] = maskedPlaceholder
+			changed = true
+		}
+	}
+	if session, ok := config["session"].(map[string]interface{}); ok {
+		if credentials, ok := session["credentials"].(map[string]interface{}); ok {
+			for name, value := range credentials {
+				if secret, ok := value.(string); ok && secret != "" {
+					credentials[name] = maskedPlaceholder
+					changed = true
+				}
+			}
+		}
+	}
+	if !changed {
+		return json.RawMessage(fetchConfig)
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return json.RawMessage(fetchConfig)
+	}
+	return data
+}
+
+// restoreMaskedFetchConfig 更新监控时，把原样提交的脱敏值还原为已保存的代理地址、客户端私钥和登录凭据。
+func restoreMaskedFetchConfig(incoming, existing string) string {
+	var incomingConfig, existingConfig map[string]interface{}
+	if json.Unmarshal([]byte(incoming), &incomingConfig) != nil || json.Unmarshal([]byte(existing), &existingConfig) != nil {
+		return incoming
+	}
+	changed := false
+	proxy, ok := incomingConfig["proxy"].(string)
+	existingProxy, existingOK := existingConfig["proxy"].(string)
+	if ok && existingOK {
+		if restored := restoreMaskedProxy(proxy, []string{existingProxy}); restored != proxy {
+			incomingConfig["proxy"] = restored
+			changed = true
+		}
+	}
+	incomingTLS, ok := incomingConfig["tls"].(map[string]interface{})
+	existingTLS, existingOK := existingConfig["tls"].(map[string]interface{})
+	if ok && existingOK && incomingTLS["client_key_pem"] == maskedPlaceholder {
+		incomingTLS["client_key_pem"] = existingTLS["client_key_pem"]
+		changed = true
+	}
+	if restoreMaskedCredentials(incomingConfig, existingConfig) {
+		changed = true
+	}
+	if !changed {
+		return incoming
+	}
+	data, err := json.Marshal(incomingConfig)
+	if err != nil {
+		return incoming
+	}
+	return string(data)
+}
+
+// restoreMaskedCredentials 逐项还原登录凭据：提交值等于占位值时视为未修改。
+func restoreMaskedCredentials(incomingConfig, existingConfig map[string]interface{}) bool {
+	incomingSession, ok := incomingConfig["session"].(map[string]interface{})
+	existingSession, existingOK := existingConfig["session"].(map[string]interface{})
+	if !ok || !existingOK {
+		return false
+	}
+	incoming, ok := incomingSession["credentials"].(map[string]interface{})
+	existing, existingOK := existingSession["credentials"].(map[string]interface{})
+	if !ok || !existingOK {
+		return false
+	}
+	changed := false
+	for name, value := range incoming {
+		secret, ok := value.(string)
+		saved, savedOK := existing[name].(string)
+		if ok && savedOK && secret == maskedPlaceholder {
+			incoming[name] = saved
+			changed = true
+		}
+	}
+	return changed
+}